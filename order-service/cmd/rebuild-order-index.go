@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"order-service/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rebuildOrdersBatchSize int
+	rebuildOrdersKeepOld   bool
+)
+
+var rebuildOrderIndexCmd = &cobra.Command{
+	Use:   "rebuild-order-index",
+	Short: "Membuat index orders baru dari Postgres lalu memindahkan alias tanpa downtime",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("Rebuild index orders sedang berjalan...")
+		return app.RebuildOrderIndex(rebuildOrdersBatchSize, rebuildOrdersKeepOld)
+	},
+}
+
+func init() {
+	rebuildOrderIndexCmd.Flags().IntVar(&rebuildOrdersBatchSize, "batch-size", 500, "number of orders loaded and bulk indexed per batch")
+	rebuildOrderIndexCmd.Flags().BoolVar(&rebuildOrdersKeepOld, "keep-old", true, "keep the previous index after the alias swap, pass --keep-old=false to delete it")
+	rootCmd.AddCommand(rebuildOrderIndexCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"order-service/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reindexOrdersAfterID   int64
+	reindexOrdersBatchSize int
+)
+
+var reindexOrdersCmd = &cobra.Command{
+	Use:   "reindex-orders",
+	Short: "Mengirim ulang data orders dari Postgres ke index orders secara bertahap",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("Reindex orders sedang berjalan...")
+		return app.ReindexOrders(reindexOrdersAfterID, reindexOrdersBatchSize)
+	},
+}
+
+func init() {
+	reindexOrdersCmd.Flags().Int64Var(&reindexOrdersAfterID, "after-id", 0, "resume after this order id")
+	reindexOrdersCmd.Flags().IntVar(&reindexOrdersBatchSize, "batch-size", 500, "number of orders loaded and bulk indexed per batch")
+	rootCmd.AddCommand(reindexOrdersCmd)
+}
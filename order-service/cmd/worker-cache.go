@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+	"order-service/internal/adapter/message"
+
+	"github.com/spf13/cobra"
+)
+
+var workerCacheCmd = &cobra.Command{
+	Use:   "worker-cache",
+	Short: "Menjalankan worker untuk menghapus cache produk dan customer saat ada perubahan",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Worker untuk Cache Invalidation sedang berjalan...")
+		message.StartCacheInvalidationConsumer()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCacheCmd)
+}
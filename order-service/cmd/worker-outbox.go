@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"order-service/internal/app"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	outboxInterval  int
+	outboxBatchSize int
+)
+
+var workerOutboxCmd = &cobra.Command{
+	Use:   "worker-outbox",
+	Short: "Menjalankan worker untuk mengirim event outbox ke RabbitMQ",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Worker untuk Outbox Relay sedang berjalan...")
+		app.RunOutboxRelay(time.Duration(outboxInterval)*time.Second, outboxBatchSize)
+	},
+}
+
+func init() {
+	workerOutboxCmd.Flags().IntVar(&outboxInterval, "interval", 5, "interval in seconds between relay runs")
+	workerOutboxCmd.Flags().IntVar(&outboxBatchSize, "batch-size", 100, "maximum number of events published per run")
+	rootCmd.AddCommand(workerOutboxCmd)
+}
@@ -8,6 +8,8 @@ type App struct {
 
 	JwtSecretKey string `json:"jwt_secret_key"`
 
+	InternalApiKey string `json:"internal_api_key"`
+
 	ServerTimeOut     int    `json:"server_time_out"`
 	ProductServiceUrl string `json:"product_service_url"`
 	UserServiceUrl    string `json:"user_service_url"`
@@ -15,6 +17,16 @@ type App struct {
 	LatitudeRef  string `json:"latitude_ref"`
 	LongitudeRef string `json:"longitude_ref"`
 	MaxDistance  int    `json:"max_distance"`
+
+	OutboxMaxAttempts int `json:"outbox_max_attempts"`
+
+	HttpClientMaxRetries       int `json:"http_client_max_retries"`
+	HttpClientBreakerThreshold int `json:"http_client_breaker_threshold"`
+	HttpClientBreakerCooldown  int `json:"http_client_breaker_cooldown"`
+
+	HttpClientLogLevel     string `json:"http_client_log_level"`
+	HttpClientLogBody      bool   `json:"http_client_log_body"`
+	HttpClientLogBodyLimit int    `json:"http_client_log_body_limit"`
 }
 
 type PsqlDB struct {
@@ -32,20 +44,31 @@ type RabbitMQ struct {
 	Port     string `json:"port"`
 	User     string `json:"user"`
 	Password string `json:"password"`
+
+	ConsumerMaxAttempts int `json:"consumer_max_attempts"`
+	ConsumerRetryDelay  int `json:"consumer_retry_delay"`
 }
 
 type Redis struct {
 	Host string `json:"host"`
 	Port string `json:"port"`
+
+	LookupCacheTTL int `json:"lookup_cache_ttl"`
 }
 
 type PublisherName struct {
-	ProductUpdateStock string `json:"product_update_stock"`
-	OrderPublish       string `json:"order_publish"`
+	ProductRestock  string `json:"product_restock"`
+	OrderPublish    string `json:"order_publish"`
+	ProductToOrder  string `json:"product_to_order"`
+	CustomerChanged string `json:"customer_changed"`
 }
 
 type ElasticSearch struct {
 	Host string `json:"host"`
+
+	Timeout          int `json:"timeout"`
+	BreakerThreshold int `json:"breaker_threshold"`
+	BreakerCooldown  int `json:"breaker_cooldown"`
 }
 
 type Config struct {
@@ -64,12 +87,22 @@ func NewConfig() *Config {
 			AppEnv:  viper.GetString("APP_ENV"),
 
 			JwtSecretKey:      viper.GetString("JWT_SECRET_KEY"),
+			InternalApiKey:    viper.GetString("INTERNAL_API_KEY"),
 			ServerTimeOut:     viper.GetInt("SERVER_TIME_OUT"),
 			ProductServiceUrl: viper.GetString("PRODUCT_SERVICE_URL"),
 			UserServiceUrl:    viper.GetString("USER_SERVICE_URL"),
 			LatitudeRef:       viper.GetString("LATITUDE_REF"),
 			LongitudeRef:      viper.GetString("LONGITUDE_REF"),
 			MaxDistance:       viper.GetInt("MAX_DISTANCE"),
+			OutboxMaxAttempts: viper.GetInt("OUTBOX_MAX_ATTEMPTS"),
+
+			HttpClientMaxRetries:       viper.GetInt("HTTP_CLIENT_MAX_RETRIES"),
+			HttpClientBreakerThreshold: viper.GetInt("HTTP_CLIENT_BREAKER_THRESHOLD"),
+			HttpClientBreakerCooldown:  viper.GetInt("HTTP_CLIENT_BREAKER_COOLDOWN"),
+
+			HttpClientLogLevel:     viper.GetString("HTTP_CLIENT_LOG_LEVEL"),
+			HttpClientLogBody:      viper.GetBool("HTTP_CLIENT_LOG_BODY"),
+			HttpClientLogBodyLimit: viper.GetInt("HTTP_CLIENT_LOG_BODY_LIMIT"),
 		},
 		Psql: PsqlDB{
 			Host:      viper.GetString("DATABASE_HOST"),
@@ -85,17 +118,28 @@ func NewConfig() *Config {
 			Port:     viper.GetString("RABBITMQ_PORT"),
 			User:     viper.GetString("RABBITMQ_USER"),
 			Password: viper.GetString("RABBITMQ_PASSWORD"),
+
+			ConsumerMaxAttempts: viper.GetInt("RABBITMQ_CONSUMER_MAX_ATTEMPTS"),
+			ConsumerRetryDelay:  viper.GetInt("RABBITMQ_CONSUMER_RETRY_DELAY"),
 		},
 		Redis: Redis{
 			Host: viper.GetString("REDIS_HOST"),
 			Port: viper.GetString("REDIS_PORT"),
+
+			LookupCacheTTL: viper.GetInt("REDIS_LOOKUP_CACHE_TTL"),
 		},
 		PublisherName: PublisherName{
-			ProductUpdateStock: viper.GetString("PRODUCT_UPDATE_STOCK_NAME"),
-			OrderPublish:       viper.GetString("ORDER_PUBLISH_NAME"),
+			ProductRestock:  viper.GetString("PRODUCT_RESTOCK_NAME"),
+			OrderPublish:    viper.GetString("ORDER_PUBLISH_NAME"),
+			ProductToOrder:  viper.GetString("PRODUCT_TO_ORDER_NAME"),
+			CustomerChanged: viper.GetString("CUSTOMER_CHANGED_NAME"),
 		},
 		ElasticSearch: ElasticSearch{
 			Host: viper.GetString("ELASTICSEARCH_HOST"),
+
+			Timeout:          viper.GetInt("ELASTICSEARCH_TIMEOUT"),
+			BreakerThreshold: viper.GetInt("ELASTICSEARCH_BREAKER_THRESHOLD"),
+			BreakerCooldown:  viper.GetInt("ELASTICSEARCH_BREAKER_COOLDOWN"),
 		},
 	}
 }
@@ -0,0 +1 @@
+ALTER TABLE order_items ADD COLUMN price DECIMAL(10, 2) NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "order_status_histories" (
+    id SERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NULL,
+    to_status VARCHAR(20) NOT NULL,
+    note TEXT NULL,
+    changed_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_status_histories_order_id ON order_status_histories(order_id);
\ No newline at end of file
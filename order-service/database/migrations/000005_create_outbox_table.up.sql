@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "outbox" (
+    id SERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    queue_name VARCHAR(120) NOT NULL,
+    message_id VARCHAR(120) NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_outbox_status_next_attempt_at ON outbox(status, next_attempt_at);
\ No newline at end of file
@@ -3,6 +3,7 @@ module order-service
 go 1.25.3
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/go-playground/locales v0.14.1
 	github.com/spf13/viper v1.21.0
 	gorm.io/gorm v1.25.10
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"order-service/config"
 	"order-service/internal/adapter"
@@ -19,12 +20,235 @@ type OrderHandlerInterface interface {
 	GetAllAdmin(c echo.Context) error
 	GetByIDAdmin(c echo.Context) error
 	CreateOrder(c echo.Context) error
+	UpdateStatusAdmin(c echo.Context) error
+
+	GetAllCustomer(c echo.Context) error
+	GetByIDCustomer(c echo.Context) error
+	CancelOrder(c echo.Context) error
 }
 
 type orderHandler struct {
 	orderService service.OrderServiceInterface
 }
 
+// CancelOrder implements OrderHandlerInterface.
+func (o *orderHandler) CancelOrder(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user := c.Get("user").(string)
+	if user == "" {
+		log.Errorf("[OrderHandler-1] CancelOrder: %s", "data token not found")
+		return c.JSON(http.StatusNotFound, response.ResponseError("data token not found"))
+	}
+
+	orderID, err := conv.StringToInt64(c.Param("orderID"))
+	if err != nil {
+		log.Errorf("[OrderHandler-2] CancelOrder: %v", err)
+		return c.JSON(http.StatusBadRequest, response.ResponseError("invalid orderID"))
+	}
+
+	err = o.orderService.CancelOrder(ctx, orderID, user)
+	if err != nil {
+		log.Errorf("[OrderHandler-3] CancelOrder: %v", err)
+		if err.Error() == "404" {
+			return c.JSON(http.StatusNotFound, response.ResponseError("data not found"))
+		}
+		if err.Error() == "409" {
+			return c.JSON(http.StatusConflict, response.ResponseError("order status has been changed, please reload"))
+		}
+		if err.Error() == "422" {
+			return c.JSON(http.StatusUnprocessableEntity, response.ResponseError("order can no longer be cancelled"))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ResponseError(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.ResponseSuccess("success", nil))
+}
+
+// GetByIDCustomer implements OrderHandlerInterface.
+func (o *orderHandler) GetByIDCustomer(c echo.Context) error {
+	var (
+		ctx       = c.Request().Context()
+		respOrder = response.OrderCustomerDetail{}
+	)
+
+	user := c.Get("user").(string)
+	if user == "" {
+		log.Errorf("[OrderHandler-1] GetByIDCustomer: %s", "data token not found")
+		return c.JSON(http.StatusNotFound, response.ResponseError("data token not found"))
+	}
+
+	orderID, err := conv.StringToInt64(c.Param("orderID"))
+	if err != nil {
+		log.Errorf("[OrderHandler-2] GetByIDCustomer: %v", err)
+		return c.JSON(http.StatusBadRequest, response.ResponseError("invalid orderID"))
+	}
+
+	order, err := o.orderService.GetByIDCustomer(ctx, orderID, user)
+	if err != nil {
+		log.Errorf("[OrderHandler-3] GetByIDCustomer: %v", err)
+		if err.Error() == "404" {
+			return c.JSON(http.StatusNotFound, response.ResponseError("data not found"))
+		}
+		if err.Error() == "503" {
+			return c.JSON(http.StatusServiceUnavailable, response.ResponseError("service temporarily unavailable, please try again"))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ResponseError(err.Error()))
+	}
+
+	respOrder.ID = order.ID
+	respOrder.OrderCode = order.OrderCode
+	respOrder.OrderDateTime = order.OrderDate
+	respOrder.Status = order.Status
+	respOrder.ShippingType = order.ShippingType
+	respOrder.ShippingFee = order.ShippingFee
+	respOrder.Remarks = order.Remarks
+	respOrder.TotalAmount = order.TotalAmount
+
+	for _, val := range order.OrderItems {
+		respOrder.OrderDetail = append(respOrder.OrderDetail, response.OrderDetail{
+			ProductName:  val.ProductName,
+			ProductImage: val.ProductImage,
+			ProductPrice: val.Price,
+			Quantity:     val.Quantity,
+		})
+	}
+
+	for _, val := range order.StatusHistories {
+		respOrder.StatusHistory = append(respOrder.StatusHistory, response.OrderStatusHistory{
+			FromStatus: val.FromStatus,
+			ToStatus:   val.ToStatus,
+			Note:       val.Note,
+			ChangedBy:  val.ChangedBy,
+			ChangedAt:  val.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, response.ResponseSuccess("success", respOrder))
+}
+
+// GetAllCustomer implements OrderHandlerInterface.
+func (o *orderHandler) GetAllCustomer(c echo.Context) error {
+	var (
+		ctx        = c.Request().Context()
+		respOrders = []response.OrderCustomerList{}
+	)
+
+	user := c.Get("user").(string)
+	if user == "" {
+		log.Errorf("[OrderHandler-1] GetAllCustomer: %s", "data token not found")
+		return c.JSON(http.StatusNotFound, response.ResponseError("data token not found"))
+	}
+
+	var page int64 = 1
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		page, _ = conv.StringToInt64(pageStr)
+		if page <= 0 {
+			page = 1
+		}
+	}
+
+	var perPage int64 = 10
+	if perPageStr := c.QueryParam("perPage"); perPageStr != "" {
+		perPage, _ = conv.StringToInt64(perPageStr)
+		if perPage <= 0 {
+			perPage = 10
+		}
+	}
+
+	reqEntity := entity.QueryStringEntity{
+		Search: c.QueryParam("search"),
+		Status: c.QueryParam("status"),
+		Page:   page,
+		Limit:  perPage,
+	}
+
+	results, totalData, totalPage, err := o.orderService.GetAllCustomer(ctx, reqEntity, user)
+	if err != nil {
+		log.Errorf("[OrderHandler-2] GetAllCustomer: %v", err)
+		if err.Error() == "404" {
+			return c.JSON(http.StatusNotFound, response.ResponseError("data not found"))
+		}
+		if err.Error() == "401" {
+			return c.JSON(http.StatusUnauthorized, response.ResponseError("data token not valid"))
+		}
+		if err.Error() == "503" {
+			return c.JSON(http.StatusServiceUnavailable, response.ResponseError("service temporarily unavailable, please try again"))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ResponseError(err.Error()))
+	}
+
+	for _, result := range results {
+		var (
+			productImage string
+			totalItem    int64
+		)
+		for _, val := range result.OrderItems {
+			productImage = val.ProductImage
+			totalItem += val.Quantity
+		}
+
+		respOrders = append(respOrders, response.OrderCustomerList{
+			ID:            result.ID,
+			OrderCode:     result.OrderCode,
+			ProductImage:  productImage,
+			OrderDateTime: result.OrderDate,
+			Status:        result.Status,
+			TotalItem:     totalItem,
+			TotalAmount:   result.TotalAmount,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response.ResponseSuccessWithPagination("success", respOrders, page, totalData, totalPage, perPage))
+}
+
+// UpdateStatusAdmin implements OrderHandlerInterface.
+func (o *orderHandler) UpdateStatusAdmin(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = request.UpdateOrderStatusRequest{}
+	)
+
+	user := c.Get("user").(string)
+	if user == "" {
+		log.Errorf("[OrderHandler-1] UpdateStatusAdmin: %s", "data token not found")
+		return c.JSON(http.StatusNotFound, response.ResponseError("data token not found"))
+	}
+
+	orderID, err := conv.StringToInt64(c.Param("orderID"))
+	if err != nil {
+		log.Errorf("[OrderHandler-2] UpdateStatusAdmin: %v", err)
+		return c.JSON(http.StatusBadRequest, response.ResponseError("invalid orderID"))
+	}
+
+	if err := c.Bind(&req); err != nil {
+		log.Errorf("[OrderHandler-3] UpdateStatusAdmin: %v", err)
+		return c.JSON(http.StatusBadRequest, response.ResponseError(err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+		log.Errorf("[OrderHandler-4] UpdateStatusAdmin: %v", err)
+		return c.JSON(http.StatusBadRequest, response.ResponseError(err.Error()))
+	}
+
+	err = o.orderService.UpdateStatus(ctx, orderID, req.Status, req.Note, user)
+	if err != nil {
+		log.Errorf("[OrderHandler-5] UpdateStatusAdmin: %v", err)
+		if err.Error() == "404" {
+			return c.JSON(http.StatusNotFound, response.ResponseError("data not found"))
+		}
+		if err.Error() == "409" {
+			return c.JSON(http.StatusConflict, response.ResponseError("order status has been changed, please reload"))
+		}
+		if err.Error() == "422" {
+			return c.JSON(http.StatusUnprocessableEntity, response.ResponseError("status transition not allowed"))
+		}
+		return c.JSON(http.StatusInternalServerError, response.ResponseError(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.ResponseSuccess("success", nil))
+}
+
 // CreateOrder implements OrderHandlerInterface.
 func (o *orderHandler) CreateOrder(c echo.Context) error {
 	var (
@@ -70,6 +294,25 @@ func (o *orderHandler) CreateOrder(c echo.Context) error {
 	orderID, err := o.orderService.CreateOrder(ctx, reqEntity, user)
 	if err != nil {
 		log.Errorf("[OrderHandler-4] CreateOrder: %v", err)
+		var stockErr *entity.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			return c.JSON(http.StatusConflict, response.DefaultResponse{
+				Message: "insufficient stock",
+				Data:    stockErr.Items,
+			})
+		}
+		if err.Error() == "404" {
+			return c.JSON(http.StatusNotFound, response.ResponseError("product not found"))
+		}
+		if err.Error() == "422" {
+			return c.JSON(http.StatusUnprocessableEntity, response.ResponseError("total amount does not match order items"))
+		}
+		if err.Error() == "401" {
+			return c.JSON(http.StatusUnauthorized, response.ResponseError("data token not valid"))
+		}
+		if err.Error() == "503" {
+			return c.JSON(http.StatusServiceUnavailable, response.ResponseError("service temporarily unavailable, please try again"))
+		}
 		return c.JSON(http.StatusInternalServerError, response.ResponseError(err.Error()))
 	}
 
@@ -110,6 +353,12 @@ func (o *orderHandler) GetByIDAdmin(c echo.Context) error {
 		if err.Error() == "404" {
 			return c.JSON(http.StatusNotFound, response.ResponseError("data not found"))
 		}
+		if err.Error() == "401" {
+			return c.JSON(http.StatusUnauthorized, response.ResponseError("data token not valid"))
+		}
+		if err.Error() == "503" {
+			return c.JSON(http.StatusServiceUnavailable, response.ResponseError("service temporarily unavailable, please try again"))
+		}
 		return c.JSON(http.StatusInternalServerError, response.ResponseError(err.Error()))
 	}
 
@@ -137,6 +386,16 @@ func (o *orderHandler) GetByIDAdmin(c echo.Context) error {
 		})
 	}
 
+	for _, val := range order.StatusHistories {
+		respOrder.StatusHistory = append(respOrder.StatusHistory, response.OrderStatusHistory{
+			FromStatus: val.FromStatus,
+			ToStatus:   val.ToStatus,
+			Note:       val.Note,
+			ChangedBy:  val.ChangedBy,
+			ChangedAt:  val.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
 	return c.JSON(http.StatusOK, response.ResponseSuccess("success", respOrder))
 
 }
@@ -189,6 +448,12 @@ func (o *orderHandler) GetAllAdmin(c echo.Context) error {
 		if err.Error() == "404" {
 			return c.JSON(http.StatusNotFound, response.ResponseError("data not found"))
 		}
+		if err.Error() == "401" {
+			return c.JSON(http.StatusUnauthorized, response.ResponseError("data token not valid"))
+		}
+		if err.Error() == "503" {
+			return c.JSON(http.StatusServiceUnavailable, response.ResponseError("service temporarily unavailable, please try again"))
+		}
 		return c.JSON(http.StatusInternalServerError, response.ResponseError(err.Error()))
 	}
 
@@ -218,10 +483,14 @@ func NewOrderHandler(orderService service.OrderServiceInterface, e *echo.Echo, c
 	mid := adapter.NewMiddlewareAdapter(cfg)
 	authGroup := e.Group("/auth", mid.CheckToken())
 	authGroup.POST("/orders", ordHandler.CreateOrder, mid.DistanceCheck())
+	authGroup.GET("/orders", ordHandler.GetAllCustomer)
+	authGroup.GET("/orders/:orderID", ordHandler.GetByIDCustomer)
+	authGroup.POST("/orders/:orderID/cancel", ordHandler.CancelOrder)
 
 	adminGroup := e.Group("/admin", mid.CheckToken())
 	adminGroup.GET("/orders", ordHandler.GetAllAdmin)
 	adminGroup.GET("/orders/:orderID", ordHandler.GetByIDAdmin)
+	adminGroup.PUT("/orders/:orderID/status", ordHandler.UpdateStatusAdmin)
 
 	return ordHandler
 }
@@ -1,16 +1,21 @@
 package request
 
 type CreateOrderRequest struct {
-	BuyerID      int64                `json:"buyer_id" validate:"required"`
+	BuyerID      int64                `json:"buyer_id"`
 	OrderDate    string               `json:"order_date" validate:"required"`
-	TotalAmount  int64                `json:"total_amount" validate:"required"`
+	TotalAmount  int64                `json:"total_amount"`
 	ShippingType string               `json:"shipping_type" validate:"required"`
 	Remarks      string               `json:"remarks"`
 	OrderTime    string               `json:"order_time" validate:"required"`
-	OrderDetails []OrderDetailRequest `json:"order_details" validate:"required"`
+	OrderDetails []OrderDetailRequest `json:"order_details" validate:"required,min=1,dive"`
 }
 
 type OrderDetailRequest struct {
 	ProductID int64 `json:"product_id" validate:"required"`
-	Quantity  int64 `json:"quantity" validate:"required"`
+	Quantity  int64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=Confirmed Processing Shipped ReadyForPickup Completed Cancelled"`
+	Note   string `json:"note"`
 }
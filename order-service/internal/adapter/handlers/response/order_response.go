@@ -11,17 +11,18 @@ type OrderAdminList struct {
 }
 
 type OrderAdminDetail struct {
-	ID            int64         `json:"id"`
-	OrderCode     string        `json:"order_code"`
-	ProductImage  string        `json:"product_image"`
-	OrderDateTime string        `json:"order_datetime"`
-	Status        string        `json:"status"`
-	PaymentMethod string        `json:"payment_method"`
-	ShippingFee   int64         `json:"shipping_fee"`
-	Remarks       string        `json:"remarks"`
-	TotalAmount   int64         `json:"total_amount"`
-	Customer      CustomerOrder `json:"customer"`
-	OrderDetail   []OrderDetail `json:"customer_detail"`
+	ID            int64                `json:"id"`
+	OrderCode     string               `json:"order_code"`
+	ProductImage  string               `json:"product_image"`
+	OrderDateTime string               `json:"order_datetime"`
+	Status        string               `json:"status"`
+	PaymentMethod string               `json:"payment_method"`
+	ShippingFee   int64                `json:"shipping_fee"`
+	Remarks       string               `json:"remarks"`
+	TotalAmount   int64                `json:"total_amount"`
+	Customer      CustomerOrder        `json:"customer"`
+	OrderDetail   []OrderDetail        `json:"customer_detail"`
+	StatusHistory []OrderStatusHistory `json:"status_history"`
 }
 
 type CustomerOrder struct {
@@ -38,3 +39,34 @@ type OrderDetail struct {
 	ProductPrice int64  `json:"product_price"`
 	Quantity     int64  `json:"quantity"`
 }
+
+type OrderStatusHistory struct {
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Note       string `json:"note"`
+	ChangedBy  int64  `json:"changed_by"`
+	ChangedAt  string `json:"changed_at"`
+}
+
+type OrderCustomerList struct {
+	ID            int64  `json:"id"`
+	OrderCode     string `json:"order_code"`
+	ProductImage  string `json:"product_image"`
+	OrderDateTime string `json:"order_datetime"`
+	Status        string `json:"status"`
+	TotalItem     int64  `json:"total_item"`
+	TotalAmount   int64  `json:"total_amount"`
+}
+
+type OrderCustomerDetail struct {
+	ID            int64                `json:"id"`
+	OrderCode     string               `json:"order_code"`
+	OrderDateTime string               `json:"order_datetime"`
+	Status        string               `json:"status"`
+	ShippingType  string               `json:"shipping_type"`
+	ShippingFee   int64                `json:"shipping_fee"`
+	Remarks       string               `json:"remarks"`
+	TotalAmount   int64                `json:"total_amount"`
+	OrderDetail   []OrderDetail        `json:"order_detail"`
+	StatusHistory []OrderStatusHistory `json:"status_history"`
+}
@@ -2,52 +2,133 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"order-service/config"
+	"order-service/utils/breaker"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 )
 
+const (
+	defaultMaxRetries       = 2
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30
+	retryBaseDelay          = 200 * time.Millisecond
+	maxErrorBodySize        = 64 << 10
+	defaultLogBodyLimit     = 1024
+)
+
 type HttpClient interface {
-	Connect()
-	CallURL(method, url string, header map[string]string, rawData []byte) (*http.Response, error)
+	CallURL(ctx context.Context, method, url string, header map[string]string, rawData []byte) (*http.Response, error)
+}
+
+// StatusError is returned by CallURL when the other service answers with a
+// non-2xx status. The response body has already been read into Body.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d", e.Method, e.URL, e.StatusCode)
 }
 
 type Options struct {
-	timeout int
-	http    *http.Client
-	logger  echo.Logger
+	http       *http.Client
+	logger     echo.Logger
+	maxRetries int
+	threshold  int
+	cooldown   time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*breaker.Breaker
 }
 
+// loggingTransport logs every call made to the other services. Method, URL and
+// status are logged at INFO, redacted headers at DEBUG, and redacted, truncated
+// bodies at DEBUG only when logBody is enabled.
 type loggingTransport struct {
-	logger echo.Logger
+	logger       echo.Logger
+	next         http.RoundTripper
+	logBody      bool
+	logBodyLimit int
 }
 
-func NewHttpClient(cfg *config.Config) HttpClient {
-	opt := new(Options)
-	opt.timeout = cfg.App.ServerTimeOut
-	return opt
+// sharedTransport is used by every client so connections to the other
+// services are pooled instead of being opened per request.
+var sharedTransport = newTransport()
+
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 20
+	transport.IdleConnTimeout = 90 * time.Second
+	return transport
 }
 
-func (o *Options) Connect() {
+func NewHttpClient(cfg *config.Config) HttpClient {
 	e := echo.New()
 	e.Logger.SetLevel(log.INFO)
 
-	httpClient := &http.Client{
-		Timeout:   time.Duration(o.timeout) * time.Second,
-		Transport: &loggingTransport{logger: e.Logger},
+	transportLogger := echo.New().Logger
+	transportLogger.SetLevel(logLevel(cfg.App.HttpClientLogLevel))
+
+	logBodyLimit := cfg.App.HttpClientLogBodyLimit
+	if logBodyLimit <= 0 {
+		logBodyLimit = defaultLogBodyLimit
+	}
+
+	maxRetries := cfg.App.HttpClientMaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	} else if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	threshold := cfg.App.HttpClientBreakerThreshold
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+
+	cooldown := cfg.App.HttpClientBreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
 	}
 
-	o.http = httpClient
-	o.logger = e.Logger
+	return &Options{
+		http: &http.Client{
+			Timeout: time.Duration(cfg.App.ServerTimeOut) * time.Second,
+			Transport: &loggingTransport{
+				logger:       transportLogger,
+				next:         sharedTransport,
+				logBody:      cfg.App.HttpClientLogBody,
+				logBodyLimit: logBodyLimit,
+			},
+		},
+		logger:     e.Logger,
+		maxRetries: maxRetries,
+		threshold:  threshold,
+		cooldown:   time.Duration(cooldown) * time.Second,
+		breakers:   map[string]*breaker.Breaker{},
+	}
 }
 
-func (o *Options) CallURL(method, url string, header map[string]string, rawData []byte) (*http.Response, error) {
-	o.Connect()
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(rawData))
+// CallURL sends the request and returns the response for 2xx statuses, a
+// *StatusError for any other status, or the transport error. GET and HEAD
+// requests are retried with backoff on network errors and on 429, 502, 503 and
+// 504. Each host has its own circuit breaker; while it is open CallURL fails
+// straight away with an error wrapping breaker.ErrOpen.
+func (o *Options) CallURL(ctx context.Context, method, url string, header map[string]string, rawData []byte) (*http.Response, error) {
+	req, err := o.newRequest(ctx, method, url, header, rawData)
 	if err != nil {
 		o.logger.Errorj(log.JSON{
 			"message": "[CallURL-1] Failed To Prepare Request Client HTTP",
@@ -56,38 +137,143 @@ func (o *Options) CallURL(method, url string, header map[string]string, rawData
 		return nil, err
 	}
 
-	if len(header) > 0 {
-		for key, value := range header {
-			req.Header.Set(key, value)
-		}
+	host := req.URL.Host
+	hostBreaker := o.breaker(host)
+
+	attempts := 1
+	if method == http.MethodGet || method == http.MethodHead {
+		attempts += o.maxRetries
 	}
 
-	resp, err := o.http.Do(req)
-	if err != nil {
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(retryBaseDelay << (attempt - 2)):
+			}
+
+			if req, err = o.newRequest(ctx, method, url, header, rawData); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = hostBreaker.Allow(); err != nil {
+			o.logger.Errorj(log.JSON{
+				"message": "[CallURL-2] Circuit Breaker Open For Host",
+				"host":    host,
+			})
+			return nil, fmt.Errorf("%s: %w", host, err)
+		}
+
+		resp, err := o.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				hostBreaker.Ignore()
+				return nil, err
+			}
+
+			hostBreaker.Failure()
+			o.logger.Errorj(log.JSON{
+				"message": "[CallURL-3] Failed To DO Request Client HTTP",
+				"attempt": attempt,
+				"error":   err.Error(),
+			})
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+			hostBreaker.Success()
+			return resp, nil
+		}
+
+		statusErr := readStatusError(req, resp)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			hostBreaker.Failure()
+		} else {
+			hostBreaker.Success()
+		}
+
+		if !retryableStatus(resp.StatusCode) {
+			return nil, statusErr
+		}
+
 		o.logger.Errorj(log.JSON{
-			"message": "[CallURL-2] Failed To DO Request Client HTTP",
-			"error":   err.Error(),
+			"message": "[CallURL-4] Retryable Response Client HTTP",
+			"attempt": attempt,
+			"status":  resp.StatusCode,
 		})
+		lastErr = statusErr
+	}
+
+	return nil, lastErr
+}
+
+func (o *Options) newRequest(ctx context.Context, method, url string, header map[string]string, rawData []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(rawData))
+	if err != nil {
 		return nil, err
 	}
 
-	return resp, nil
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+func (o *Options) breaker(host string) *breaker.Breaker {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	hostBreaker, ok := o.breakers[host]
+	if !ok {
+		hostBreaker = breaker.New(o.threshold, o.cooldown)
+		o.breakers[host] = hostBreaker
+	}
+
+	return hostBreaker
+}
+
+func readStatusError(req *http.Request, resp *http.Response) *StatusError {
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return &StatusError{
+		Method:     req.Method,
+		URL:        req.URL.Redacted(),
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}
+}
+
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
 }
 
 func (lt *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Logging sebelum request
-	lt.logger.Infof("Making request to: %s %s", req.Method, req.URL)
-	lt.logger.Infof("Request Headers: %+v", req.Header)
+	lt.logger.Infof("Making request to: %s %s", req.Method, req.URL.Redacted())
+	lt.logger.Debugf("Request Headers: %+v", redactHeaders(req.Header))
 
 	// Mengganti request body karena sudah dibaca dalam fungsi logging
-	reqBody, err := io.ReadAll(req.Body)
-	if err != nil {
-		return nil, err
+	if lt.logBody && req.Body != nil {
+		reqBody, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewBuffer(reqBody))
+		lt.logger.Debugf("Request Body: %s", redactBody(reqBody, lt.logBodyLimit))
 	}
-	req.Body = io.NopCloser(bytes.NewBuffer(reqBody))
-	lt.logger.Infof("Request Body: %s", reqBody)
 
-	resp, err := http.DefaultTransport.RoundTrip(req)
+	resp, err := lt.next.RoundTrip(req)
 	if err != nil {
 		lt.logger.Infof("Request failed: %v", err)
 		return nil, err
@@ -95,14 +281,35 @@ func (lt *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 
 	// Logging setelah menerima respons
 	lt.logger.Infof("Received response with status: %s", resp.Status)
-	lt.logger.Infof("Response Headers: %+v", resp.Header)
+	lt.logger.Debugf("Response Headers: %+v", redactHeaders(resp.Header))
+
+	if !lt.logBody {
+		return resp, nil
+	}
 
 	// Menampilkan Response Body (jika ada)
 	respBody, err := io.ReadAll(resp.Body)
 	if err == nil {
-		lt.logger.Infof("Response Body: %s", respBody)
+		lt.logger.Debugf("Response Body: %s", redactBody(respBody, lt.logBodyLimit))
 	}
+	resp.Body.Close()
 	resp.Body = io.NopCloser(bytes.NewBuffer(respBody))
 
 	return resp, nil
 }
+
+// logLevel maps HTTP_CLIENT_LOG_LEVEL to a logger level, defaulting to INFO.
+func logLevel(level string) log.Lvl {
+	switch strings.ToLower(level) {
+	case "debug":
+		return log.DEBUG
+	case "warn":
+		return log.WARN
+	case "error":
+		return log.ERROR
+	case "off":
+		return log.OFF
+	}
+
+	return log.INFO
+}
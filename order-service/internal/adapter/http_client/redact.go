@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are replaced before request and response headers are logged.
+// Keys are stored in canonical form so they match whatever casing a caller used.
+var sensitiveHeaders = canonicalHeaderSet(
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Internal-Key",
+)
+
+// sensitiveFields are JSON keys whose values are replaced before a body is
+// logged, at any depth of the document. Keys are matched case-insensitively.
+var sensitiveFields = map[string]bool{
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"password":      true,
+	"email":         true,
+	"phone":         true,
+	"address":       true,
+	"buyer_email":   true,
+	"buyer_phone":   true,
+	"buyer_address": true,
+}
+
+func canonicalHeaderSet(keys ...string) map[string]bool {
+	set := map[string]bool{}
+	for _, key := range keys {
+		set[http.CanonicalHeaderKey(key)] = true
+	}
+
+	return set
+}
+
+func redactHeaders(header http.Header) http.Header {
+	clean := header.Clone()
+	for key := range clean {
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+			clean[key] = []string{redacted}
+		}
+	}
+
+	return clean
+}
+
+// redactBody masks sensitive JSON fields and cuts the result to limit bytes.
+// Bodies that are not JSON are only truncated.
+func redactBody(body []byte, limit int) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err == nil {
+		if masked, err := json.Marshal(redactValue(doc)); err == nil {
+			body = masked
+		}
+	}
+
+	if limit > 0 && len(body) > limit {
+		return fmt.Sprintf("%s... (%d bytes truncated)", body[:limit], len(body)-limit)
+	}
+
+	return string(body)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if sensitiveFields[strings.ToLower(key)] {
+				val[key] = redacted
+				continue
+			}
+			val[key] = redactValue(item)
+		}
+	case []interface{}:
+		for key, item := range val {
+			val[key] = redactValue(item)
+		}
+	}
+
+	return value
+}
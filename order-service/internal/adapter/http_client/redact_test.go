@@ -0,0 +1,42 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("X-Internal-Key", "internal-secret")
+	header["x-internal-key"] = []string{"lowercase-secret"}
+	header.Set("Content-Type", "application/json")
+
+	clean := redactHeaders(header)
+
+	for key, values := range clean {
+		if http.CanonicalHeaderKey(key) == "Content-Type" {
+			continue
+		}
+		if len(values) != 1 || values[0] != redacted {
+			t.Errorf("%s: got %v, want %s", key, values, redacted)
+		}
+	}
+
+	if got := clean.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type: got %q, want application/json", got)
+	}
+
+	if got := header.Get("X-Internal-Key"); got != "internal-secret" {
+		t.Errorf("original header changed: got %q", got)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	body := []byte(`{"data":[{"id":1,"buyer_email":"a@b.c","Token":"t"}],"password":"p"}`)
+
+	want := `{"data":[{"Token":"[REDACTED]","buyer_email":"[REDACTED]","id":1}],"password":"[REDACTED]"}`
+	if got := redactBody(body, 0); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"order-service/config"
+	"order-service/internal/adapter/repository"
+	"order-service/internal/core/domain/entity"
+
+	"github.com/labstack/gommon/log"
+)
+
+// StartCacheInvalidationConsumer drops cached products and customers when
+// product-service or user-service announce a change. Each queue is consumed on
+// its own channel; the worker stops when either consumer stops, and does not
+// start when either queue name is not configured.
+func StartCacheInvalidationConsumer() {
+	cfg := config.NewConfig()
+	conn, err := cfg.NewRabbitMQ()
+	if err != nil {
+		log.Errorf("[StartCacheInvalidationConsumer-1] Failed to connect to RabbitMQ: %v", err)
+		return
+	}
+
+	defer conn.Close()
+
+	lookupCache := repository.NewLookupCacheRepository(cfg.NewRedisClient(), cfg)
+
+	queues := []struct {
+		envKey    string
+		queueName string
+		handle    func(body []byte) error
+	}{
+		{
+			envKey:    "PRODUCT_TO_ORDER_NAME",
+			queueName: cfg.PublisherName.ProductToOrder,
+			handle: func(body []byte) error {
+				return invalidateProducts(lookupCache, body)
+			},
+		},
+		{
+			envKey:    "CUSTOMER_CHANGED_NAME",
+			queueName: cfg.PublisherName.CustomerChanged,
+			handle: func(body []byte) error {
+				return invalidateCustomer(lookupCache, body)
+			},
+		},
+	}
+
+	for _, queue := range queues {
+		if queue.queueName == "" {
+			log.Errorf("[StartCacheInvalidationConsumer-2] %s is not set", queue.envKey)
+			return
+		}
+	}
+
+	errCh := make(chan error, len(queues))
+	for _, queue := range queues {
+		ch, err := conn.Channel()
+		if err != nil {
+			log.Errorf("[StartCacheInvalidationConsumer-3] Failed to open a channel: %v", err)
+			return
+		}
+		defer ch.Close()
+
+		go func(queueName string, handle func(body []byte) error) {
+			errCh <- consumeWithRetry(ch, cfg, queueName, handle)
+		}(queue.queueName, queue.handle)
+	}
+
+	log.Info("RabbitMQ Consumer cache invalidation started...")
+
+	for range queues {
+		if err = <-errCh; err != nil {
+			log.Fatalf("[StartCacheInvalidationConsumer-4] Consumer stopped: %v", err)
+		}
+	}
+}
+
+func invalidateProducts(lookupCache repository.LookupCacheRepositoryInterface, body []byte) error {
+	var event entity.ProductChangedEntity
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Errorf("[invalidateProducts-1] Error decoding message: %v", err)
+		return permanent(err)
+	}
+
+	if err := lookupCache.DeleteProducts(context.Background(), event.ProductIDs); err != nil {
+		log.Errorf("[invalidateProducts-2] %v", err)
+		return err
+	}
+
+	log.Infof("[invalidateProducts-3] Cache produk %v dihapus", event.ProductIDs)
+	return nil
+}
+
+func invalidateCustomer(lookupCache repository.LookupCacheRepositoryInterface, body []byte) error {
+	var event entity.CustomerChangedEntity
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Errorf("[invalidateCustomer-1] Error decoding message: %v", err)
+		return permanent(err)
+	}
+
+	if err := lookupCache.DeleteCustomers(context.Background(), []int64{event.CustomerID}); err != nil {
+		log.Errorf("[invalidateCustomer-2] %v", err)
+		return err
+	}
+
+	log.Infof("[invalidateCustomer-3] Cache customer %d dihapus", event.CustomerID)
+	return nil
+}
@@ -6,14 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"order-service/config"
+	"order-service/internal/adapter/repository"
 	"order-service/internal/core/domain/entity"
 
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/labstack/gommon/log"
 )
 
 func StartOrderConsumer() {
-	conn, err := config.NewConfig().NewRabbitMQ()
+	cfg := config.NewConfig()
+	conn, err := cfg.NewRabbitMQ()
 	if err != nil {
 		log.Errorf("[StartOrderConsumer-1] Failed to connect to RabbitMQ: %v", err)
 		return
@@ -29,78 +34,73 @@ func StartOrderConsumer() {
 
 	defer ch.Close()
 
-	q, err := ch.QueueDeclare(
-		config.NewConfig().PublisherName.OrderPublish,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+	esClient, err := cfg.InitElasticsearch()
 	if err != nil {
-		log.Fatalf("[StartOrderConsumer-3] Failed to declare queue: %v", err)
+		log.Errorf("[StartOrderConsumer-3] Failed initialize Elasticsearch client: %v", err)
 		return
 	}
 
-	msgs, err := ch.Consume(
-		q.Name,
-		"",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Fatalf("[StartOrderConsumer-4] Failed to register consumer: %v", err)
+	if err = repository.EnsureOrderIndex(context.Background(), esClient); err != nil {
+		log.Errorf("[StartOrderConsumer-4] Failed to prepare orders index: %v", err)
 		return
 	}
 
 	log.Info("RabbitMQ Consumer order started...")
 
-	esClient, err := config.NewConfig().InitElasticsearch()
+	err = consumeWithRetry(ch, cfg, cfg.PublisherName.OrderPublish, func(body []byte) error {
+		return indexOrder(esClient, body)
+	})
 	if err != nil {
-		log.Errorf("[StartOrderConsumer-5] Failed initialize Elasticsearch client: %v", err)
-		return
+		log.Fatalf("[StartOrderConsumer-5] Consumer stopped: %v", err)
+	}
+}
+
+// indexOrder writes the order document with the ID of its latest status history
+// as an external version, so an older event that is retried after a newer one
+// cannot overwrite the newer document.
+func indexOrder(esClient *elasticsearch.Client, body []byte) error {
+	var order entity.OrderEntity
+	if err := json.Unmarshal(body, &order); err != nil {
+		log.Errorf("[indexOrder-1] Error decoding message: %v", err)
+		return permanent(err)
+	}
+
+	// Convert order struct ke JSON
+	orderJSON, err := json.Marshal(order)
+	if err != nil {
+		log.Errorf("[indexOrder-2] Error encoding order to JSON: %v", err)
+		return permanent(err)
+	}
+
+	options := []func(*esapi.IndexRequest){
+		esClient.Index.WithDocumentID(fmt.Sprintf("%d", order.ID)), // ID dokumen
+		esClient.Index.WithContext(context.Background()),
+		esClient.Index.WithRefresh("true"),
+	}
+	if version := order.IndexVersion(); version > 0 {
+		options = append(options, esClient.Index.WithVersion(int(version)), esClient.Index.WithVersionType("external"))
+	}
+
+	// Indexing ke Elasticsearch
+	res, err := esClient.Index(repository.OrderIndexName, bytes.NewReader(orderJSON), options...)
+	if err != nil {
+		log.Errorf("[indexOrder-3] Error indexing to Elasticsearch: %v", err)
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, _ := io.ReadAll(res.Body)
+	if res.StatusCode == http.StatusConflict {
+		log.Infof("[indexOrder-4] Order %d already indexed with a newer version, skipping version %d", order.ID, order.IndexVersion())
+		return nil
+	}
+
+	if res.IsError() {
+		err = fmt.Errorf("elasticsearch returned %s: %s", res.Status(), string(resBody))
+		log.Errorf("[indexOrder-5] %v", err)
+		return err
 	}
 
-	forever := make(chan bool)
-	go func() {
-		for d := range msgs {
-			var order entity.OrderEntity
-			err := json.Unmarshal(d.Body, &order)
-			if err != nil {
-				log.Errorf("[StartOrderConsumer-6] Error decoding message: %v", err)
-				continue
-			}
-
-			// Convert order struct ke JSON
-			orderJSON, err := json.Marshal(order)
-			if err != nil {
-				log.Errorf("[StartOrderConsumer-7] Error encoding order to JSON: %v", err)
-				continue
-			}
-
-			// Indexing ke Elasticsearch
-			res, err := esClient.Index(
-				"orders",                   // Nama index di Elasticsearch
-				bytes.NewReader(orderJSON), // Data JSON
-				esClient.Index.WithDocumentID(fmt.Sprintf("%d", order.ID)), // ID dokumen
-				esClient.Index.WithContext(context.Background()),
-				esClient.Index.WithRefresh("true"),
-			)
-			if err != nil {
-				log.Errorf("[StartOrderConsumer-8] Error indexing to Elasticsearch: %v", err)
-				continue
-			}
-			defer res.Body.Close()
-
-			body, _ := io.ReadAll(res.Body)
-
-			log.Infof("[StartOrderConsumer-9] Order %d berhasil diindex ke Elasticsearch %v", order.ID, string(body))
-		}
-	}()
-
-	log.Infof("[StartOrderConsumer-10] Waiting for messages. To exit press CTRL+C")
-	<-forever
+	log.Infof("[indexOrder-6] Order %d berhasil diindex ke Elasticsearch %v", order.ID, string(resBody))
+	return nil
 }
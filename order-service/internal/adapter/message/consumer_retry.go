@@ -0,0 +1,279 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"order-service/config"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/streadway/amqp"
+)
+
+const (
+	defaultConsumerMaxAttempts = 5
+	defaultConsumerRetryDelay  = 10
+	consumerPrefetch           = 10
+
+	headerAttempt       = "x-attempt"
+	headerLastError     = "x-last-error"
+	headerOriginalQueue = "x-original-queue"
+	headerDeadLetterAt  = "x-dead-lettered-at"
+)
+
+// permanentError marks a message that can never succeed, such as a payload that
+// does not decode, so it goes to the dead-letter queue without being retried.
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string {
+	return p.err.Error()
+}
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func retryQueueName(queueName string) string {
+	return queueName + ".retry"
+}
+
+func deadLetterQueueName(queueName string) string {
+	return queueName + ".dlq"
+}
+
+// declareRetryTopology declares the main queue together with its delayed retry
+// queue, which dead-letters expired messages back to the main queue, and its
+// dead-letter queue.
+func declareRetryTopology(ch *amqp.Channel, cfg *config.Config, queueName string) error {
+	retryDelay := cfg.RabbitMQ.ConsumerRetryDelay
+	if retryDelay <= 0 {
+		retryDelay = defaultConsumerRetryDelay
+	}
+
+	if _, err := ch.QueueDeclare(queueName, true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	_, err := ch.QueueDeclare(retryQueueName(queueName), true, false, false, false, amqp.Table{
+		"x-message-ttl":             int32(retryDelay * 1000),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queueName,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.QueueDeclare(deadLetterQueueName(queueName), true, false, false, false, nil)
+	return err
+}
+
+// consumeWithRetry consumes queueName with manual acknowledgment. A message is
+// acked once handle succeeds; otherwise it is moved to the retry queue with its
+// attempt count in the headers, and to the dead-letter queue once the attempts
+// are used up.
+func consumeWithRetry(ch *amqp.Channel, cfg *config.Config, queueName string, handle func(body []byte) error) error {
+	if err := declareRetryTopology(ch, cfg, queueName); err != nil {
+		return err
+	}
+
+	if err := ch.Qos(consumerPrefetch, 0, false); err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(queueName, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	maxAttempts := cfg.RabbitMQ.ConsumerMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultConsumerMaxAttempts
+	}
+
+	for d := range msgs {
+		err := handle(d.Body)
+		if err == nil {
+			if err = d.Ack(false); err != nil {
+				log.Errorf("[consumeWithRetry-1] Failed to ack message: %v", err)
+			}
+			continue
+		}
+
+		attempt := deliveryAttempt(d) + 1
+		target := retryQueueName(queueName)
+
+		var permErr *permanentError
+		if attempt >= maxAttempts || errors.As(err, &permErr) {
+			target = deadLetterQueueName(queueName)
+			log.Errorf("[consumeWithRetry-2] Dead-lettering message from %s after %d attempts: %v", queueName, attempt, err)
+		} else {
+			log.Errorf("[consumeWithRetry-3] Retrying message from %s (attempt %d): %v", queueName, attempt, err)
+		}
+
+		headers := amqp.Table{}
+		for key, val := range d.Headers {
+			headers[key] = val
+		}
+		headers[headerAttempt] = int32(attempt)
+		headers[headerLastError] = err.Error()
+		headers[headerOriginalQueue] = queueName
+		if target == deadLetterQueueName(queueName) {
+			headers[headerDeadLetterAt] = time.Now().Format(time.RFC3339)
+		}
+
+		err = ch.Publish("", target, false, false, amqp.Publishing{
+			Headers:      headers,
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Body:         d.Body,
+		})
+		if err != nil {
+			log.Errorf("[consumeWithRetry-4] Failed to move message to %s: %v", target, err)
+			d.Nack(false, true)
+			continue
+		}
+
+		if err = d.Ack(false); err != nil {
+			log.Errorf("[consumeWithRetry-5] Failed to ack message: %v", err)
+		}
+	}
+
+	return errors.New("consumer channel closed")
+}
+
+func deliveryAttempt(d amqp.Delivery) int {
+	switch val := d.Headers[headerAttempt].(type) {
+	case int32:
+		return int(val)
+	case int64:
+		return int(val)
+	case int:
+		return val
+	default:
+		return 0
+	}
+}
+
+// DeadLetterMessage is a dead-lettered message as shown by the dlq command.
+type DeadLetterMessage struct {
+	MessageID      string
+	Attempt        int
+	LastError      string
+	DeadLetteredAt string
+	Body           string
+}
+
+// InspectDeadLetters returns up to limit messages from the dead-letter queue of
+// queueName without removing them.
+func InspectDeadLetters(queueName string, limit int) ([]DeadLetterMessage, error) {
+	conn, err := config.NewConfig().NewRabbitMQ()
+	if err != nil {
+		log.Errorf("[InspectDeadLetters-1] Failed to connect to RabbitMQ: %v", err)
+		return nil, err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[InspectDeadLetters-2] Failed to open a channel: %v", err)
+		return nil, err
+	}
+	// Closing the channel returns every unacked message to the queue in order.
+	defer ch.Close()
+
+	messages := []DeadLetterMessage{}
+	for len(messages) < limit {
+		d, ok, err := ch.Get(deadLetterQueueName(queueName), false)
+		if err != nil {
+			log.Errorf("[InspectDeadLetters-3] Failed to read dead-letter queue: %v", err)
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		lastError, _ := d.Headers[headerLastError].(string)
+		deadLetteredAt, _ := d.Headers[headerDeadLetterAt].(string)
+		messages = append(messages, DeadLetterMessage{
+			MessageID:      d.MessageId,
+			Attempt:        deliveryAttempt(d),
+			LastError:      lastError,
+			DeadLetteredAt: deadLetteredAt,
+			Body:           string(d.Body),
+		})
+	}
+
+	return messages, nil
+}
+
+// ReplayDeadLetters moves up to limit messages from the dead-letter queue of
+// queueName back to queueName with a fresh attempt count.
+func ReplayDeadLetters(queueName string, limit int) (int, error) {
+	conn, err := config.NewConfig().NewRabbitMQ()
+	if err != nil {
+		log.Errorf("[ReplayDeadLetters-1] Failed to connect to RabbitMQ: %v", err)
+		return 0, err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[ReplayDeadLetters-2] Failed to open a channel: %v", err)
+		return 0, err
+	}
+	defer ch.Close()
+
+	if err = ch.Confirm(false); err != nil {
+		log.Errorf("[ReplayDeadLetters-3] Failed to enable publisher confirms: %v", err)
+		return 0, err
+	}
+	confirms := ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+
+	replayed := 0
+	for replayed < limit {
+		d, ok, err := ch.Get(deadLetterQueueName(queueName), false)
+		if err != nil {
+			log.Errorf("[ReplayDeadLetters-4] Failed to read dead-letter queue: %v", err)
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		headers := amqp.Table{}
+		for key, val := range d.Headers {
+			headers[key] = val
+		}
+		delete(headers, headerAttempt)
+		delete(headers, headerDeadLetterAt)
+
+		err = ch.Publish("", queueName, false, false, amqp.Publishing{
+			Headers:      headers,
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Body:         d.Body,
+		})
+		if err != nil {
+			log.Errorf("[ReplayDeadLetters-5] Failed to republish message: %v", err)
+			return replayed, err
+		}
+
+		if confirm := <-confirms; !confirm.Ack {
+			err = fmt.Errorf("replay of message %q was not acknowledged by the broker", d.MessageId)
+			log.Errorf("[ReplayDeadLetters-6] %v", err)
+			return replayed, err
+		}
+
+		if err = d.Ack(false); err != nil {
+			log.Errorf("[ReplayDeadLetters-7] Failed to ack dead-lettered message: %v", err)
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
@@ -1,126 +1,37 @@
 package message
 
 import (
-	"encoding/json"
 	"order-service/config"
-	"order-service/internal/core/domain/entity"
 
 	"github.com/labstack/gommon/log"
 	"github.com/streadway/amqp"
 )
 
 type PublishRabbitMQInterface interface {
-	PublishUpdateStock(productID int64, quantity int64)
-	PublishOrderToQueue(order entity.OrderEntity) error
+	Publish(queueName, messageID string, body []byte) error
 }
 
 type PublishRabbitMQ struct {
-	cfg *config.Config
+	cfg       *config.Config
+	publisher RabbitMQPublisherInterface
 }
 
-// PublishOrderToQueue implements PublishRabbitMQInterface.
-func (p *PublishRabbitMQ) PublishOrderToQueue(order entity.OrderEntity) error {
-	conn, err := p.cfg.NewRabbitMQ()
+// Publish implements PublishRabbitMQInterface.
+func (p *PublishRabbitMQ) Publish(queueName, messageID string, body []byte) error {
+	err := p.publisher.Publish(queueName, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageID,
+		Body:         body,
+	})
 	if err != nil {
-		log.Errorf("[PublishOrderToQueue-1] Failed to connect to RabbitMQ: %v", err)
-		return err
-	}
-
-	defer conn.Close()
-
-	ch, err := conn.Channel()
-	if err != nil {
-		log.Errorf("[PublishOrderToQueue-2] Failed to open a channel: %v", err)
-		return err
-	}
-
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(
-		p.cfg.PublisherName.OrderPublish,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Errorf("[PublishOrderToQueue-3] Failed to declare queue: %v", err)
-		return err
-	}
-
-	data, _ := json.Marshal(order)
-	err = ch.Publish(
-		"",
-		q.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        data,
-		},
-	)
-	if err != nil {
-		log.Errorf("[PublishOrderToQueue-4] Failed to publish message: %v", err)
+		log.Errorf("[Publish-1] Failed to publish message: %v", err)
 		return err
 	}
 
 	return nil
 }
 
-// PublishUpdateStock implements PublishRabbitMQInterface.
-func (p *PublishRabbitMQ) PublishUpdateStock(productID int64, quantity int64) {
-	conn, err := p.cfg.NewRabbitMQ()
-	if err != nil {
-		log.Errorf("[PublishUpdateStock-1] Failed to connect to RabbitMQ: %v", err)
-		return
-	}
-
-	defer conn.Close()
-
-	ch, err := conn.Channel()
-	if err != nil {
-		log.Errorf("[PublishUpdateStock-2] Failed to open a channel: %v", err)
-		return
-	}
-
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(p.cfg.PublisherName.ProductUpdateStock, true, false, false, false, nil)
-	if err != nil {
-		log.Errorf("[PublishUpdateStock-3] Failed to declare a queue: %v", err)
-		return
-	}
-
-	order := entity.PublishOrderItemEntity{
-		ProductID: productID,
-		Quantity:  quantity,
-	}
-
-	data, err := json.Marshal(order)
-	if err != nil {
-		log.Errorf("[PublishUpdateStock-4] Failed to marshal order: %v", err)
-		return
-	}
-
-	err = ch.Publish(
-		"",
-		q.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        data,
-		},
-	)
-	if err != nil {
-		log.Errorf("[PublishUpdateStock-5] Failed to publish message: %v", err)
-		return
-	}
-
-	log.Info("Pesan order dikirim ke RabbitMQ")
-}
-
-func NewPublisherRabbitMQ(cfg *config.Config) PublishRabbitMQInterface {
-	return &PublishRabbitMQ{cfg: cfg}
+func NewPublisherRabbitMQ(cfg *config.Config, publisher RabbitMQPublisherInterface) PublishRabbitMQInterface {
+	return &PublishRabbitMQ{cfg: cfg, publisher: publisher}
 }
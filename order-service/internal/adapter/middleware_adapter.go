@@ -98,8 +98,8 @@ func (m *middlewareAdapter) CheckToken() echo.MiddlewareFunc {
 
 			getSession, err := redisConn.Get(c.Request().Context(), tokenString).Result()
 			if err != nil || len(getSession) == 0 {
-				log.Errorf("[MiddlewareAdapter-3] CheckToken: %s", err.Error())
-				return c.JSON(http.StatusUnauthorized, response.ResponseError(err.Error()))
+				log.Errorf("[MiddlewareAdapter-3] CheckToken: session not found: %v", err)
+				return c.JSON(http.StatusUnauthorized, response.ResponseError("session expired or revoked"))
 			}
 
 			jwtUserData := entity.JwtUserData{}
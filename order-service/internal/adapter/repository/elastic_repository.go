@@ -6,99 +6,177 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"net/http"
+	"order-service/config"
 	"order-service/internal/core/domain/entity"
-	"strings"
+	"order-service/utils/breaker"
+	"order-service/utils/esquery"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
 )
 
+const (
+	defaultElasticTimeout          = 2
+	defaultElasticBreakerThreshold = 3
+	defaultElasticBreakerCooldown  = 30
+)
+
 type ElasticRepositoryInterface interface {
 	SearchOrderElastic(ctx context.Context, queryString entity.QueryStringEntity) ([]entity.OrderEntity, int64, int64, error)
+	Ping(ctx context.Context) error
 }
 
 type elasticRepository struct {
 	esClient *elasticsearch.Client
+	breaker  *breaker.Breaker
+	timeout  time.Duration
+}
+
+// Ping implements ElasticRepositoryInterface.
+// It checks the cluster health and opens the circuit breaker when the cluster
+// cannot be reached or is red, so searches go to Postgres until it recovers.
+func (e *elasticRepository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	res, err := e.esClient.Cluster.Health(e.esClient.Cluster.Health.WithContext(ctx))
+	if err != nil {
+		e.breaker.Trip()
+		return err
+	}
+	defer res.Body.Close()
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if res.IsError() {
+		err = fmt.Errorf("elasticsearch returned %s", res.Status())
+	} else if err = json.NewDecoder(res.Body).Decode(&health); err == nil && health.Status == "red" {
+		err = fmt.Errorf("elasticsearch cluster status is %s", health.Status)
+	}
+
+	if err != nil {
+		e.breaker.Trip()
+		return err
+	}
+
+	e.breaker.Success()
+	return nil
 }
 
 // SearchOrderElastic implements ElasticRepositoryInterface.
 func (e *elasticRepository) SearchOrderElastic(ctx context.Context, query entity.QueryStringEntity) ([]entity.OrderEntity, int64, int64, error) {
 	from := (query.Page - 1) * query.Limit
 
-	statusFilter := ""
+	boolQuery := esquery.BoolQuery{}
+	if query.BuyerID > 0 {
+		boolQuery.Filter = append(boolQuery.Filter, esquery.Term("buyer_id", query.BuyerID))
+	}
+
 	if query.Status != "" {
-		statusFilter = fmt.Sprintf(`{ "match": { "status": "%s" } },`, query.Status)
+		boolQuery.Filter = append(boolQuery.Filter, esquery.Term("status.keyword", query.Status))
 	}
 
-	searchFilter := `{"match_all": {}}`
 	if query.Search != "" {
-		searchFilter = fmt.Sprintf(`{ "multi_match": { "query": "%s", "fields": ["order_code", "status", "buyer_name"] } }`, query.Search)
+		boolQuery.Must = append(boolQuery.Must, esquery.MultiMatch(query.Search, "order_code", "status", "buyer_name"))
+	} else {
+		boolQuery.Must = append(boolQuery.Must, esquery.MatchAll())
 	}
+
 	// Query Elasticsearch dengan filtering dan pagination
-	mainQuery := fmt.Sprintf(`{
-		"from": %d,
-		"size": %d,
-		"query": {
-			"bool": {
-				"must": [
-					%s
-					%s
-				]
-			}
-		},
-		"sort": [
-			{ "id": "asc" }
-		]
-	}`, from, query.Limit, statusFilter, searchFilter)
+	searchRequest := esquery.SearchRequest{
+		From:  int(from),
+		Size:  int(query.Limit),
+		Query: boolQuery.Query(),
+		Sort:  []esquery.Sort{esquery.SortBy("id", esquery.SortAsc)},
+	}
+
+	body, err := searchRequest.Reader()
+	if err != nil {
+		log.Printf("Error encoding query: %s", err)
+		return nil, 0, 0, err
+	}
 
 	// Kirim query ke Elasticsearch
+	if err = e.breaker.Allow(); err != nil {
+		return nil, 0, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
 	res, err := e.esClient.Search(
 		e.esClient.Search.WithContext(ctx),
-		e.esClient.Search.WithIndex("orders"),
-		e.esClient.Search.WithBody(strings.NewReader(mainQuery)),
-		e.esClient.Search.WithPretty(),
+		e.esClient.Search.WithIndex(OrderIndexName),
+		e.esClient.Search.WithBody(body),
 	)
-
 	if err != nil {
+		e.breaker.Failure()
 		log.Printf("Error searching Elasticsearch: %s", err)
 		return nil, 0, 0, err
 	}
 	defer res.Body.Close()
 
-	// Decode response
-	var result map[string]interface{}
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		log.Printf("Error decoding response: %s", err)
+	// Hanya error server yang dihitung sebagai kegagalan cluster
+	if res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests {
+		e.breaker.Failure()
+	} else {
+		e.breaker.Success()
+	}
+
+	if res.IsError() {
+		err = fmt.Errorf("elasticsearch returned %s", res.Status())
+		log.Printf("Error searching Elasticsearch: %s", err)
 		return nil, 0, 0, err
 	}
 
-	// Ambil total data
-	totalData := 0
-	if hitsTotal, found := result["hits"].(map[string]interface{})["total"].(map[string]interface{}); found {
-		totalData = int(hitsTotal["value"].(float64))
+	// Decode response
+	result, err := esquery.DecodeSearchResponse(res.Body)
+	if err != nil {
+		log.Printf("Error decoding response: %s", err)
+		return nil, 0, 0, err
 	}
 
 	// Hitung total halaman
+	totalData := result.Hits.Total.Value
 	totalPage := 0
 	if query.Limit > 0 {
 		totalPage = int(math.Ceil(float64(totalData) / float64(query.Limit)))
 	}
 
-	// Parsing hasil pencarian ke struct domain.Product
 	orders := []entity.OrderEntity{}
-	hits, found := result["hits"].(map[string]interface{})["hits"].([]interface{})
-	if found {
-		for _, hit := range hits {
-			source := hit.(map[string]interface{})["_source"]
-			data, _ := json.Marshal(source)
-			var order entity.OrderEntity
-			json.Unmarshal(data, &order)
-			orders = append(orders, order)
+	for _, hit := range result.Hits.Hits {
+		var order entity.OrderEntity
+		if err := json.Unmarshal(hit.Source, &order); err != nil {
+			log.Printf("Error decoding order %s: %s", hit.ID, err)
+			continue
 		}
+		orders = append(orders, order)
 	}
 
-	return orders, int64(totalData), int64(totalPage), nil
+	return orders, totalData, int64(totalPage), nil
 }
 
-func NewElasticRepository(es *elasticsearch.Client) ElasticRepositoryInterface {
-	return &elasticRepository{esClient: es}
+func NewElasticRepository(es *elasticsearch.Client, cfg *config.Config) ElasticRepositoryInterface {
+	timeout := cfg.ElasticSearch.Timeout
+	if timeout <= 0 {
+		timeout = defaultElasticTimeout
+	}
+
+	threshold := cfg.ElasticSearch.BreakerThreshold
+	if threshold <= 0 {
+		threshold = defaultElasticBreakerThreshold
+	}
+
+	cooldown := cfg.ElasticSearch.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultElasticBreakerCooldown
+	}
+
+	return &elasticRepository{
+		esClient: es,
+		breaker:  breaker.New(threshold, time.Duration(cooldown)*time.Second),
+		timeout:  time.Duration(timeout) * time.Second,
+	}
 }
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"order-service/config"
+	"order-service/internal/core/domain/entity"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/gommon/log"
+)
+
+const defaultLookupCacheTTL = 600
+
+// LookupCacheRepositoryInterface caches the products and customers fetched from
+// product-service and user-service. Redis errors are logged and treated as
+// cache misses so order views keep working when Redis is unavailable.
+type LookupCacheRepositoryInterface interface {
+	GetProducts(ctx context.Context, productIDs []int64) map[int64]entity.ProductResponseEntity
+	SetProducts(ctx context.Context, products map[int64]entity.ProductResponseEntity)
+	DeleteProducts(ctx context.Context, productIDs []int64) error
+
+	GetCustomers(ctx context.Context, customerIDs []int64) map[int64]entity.CustomerResponseEntity
+	SetCustomers(ctx context.Context, customers map[int64]entity.CustomerResponseEntity)
+	DeleteCustomers(ctx context.Context, customerIDs []int64) error
+}
+
+type lookupCacheRepository struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+// GetProducts implements LookupCacheRepositoryInterface.
+func (l *lookupCacheRepository) GetProducts(ctx context.Context, productIDs []int64) map[int64]entity.ProductResponseEntity {
+	products := map[int64]entity.ProductResponseEntity{}
+	l.get(ctx, productCacheKeys(productIDs), func(index int, raw string) error {
+		var product entity.ProductResponseEntity
+		if err := json.Unmarshal([]byte(raw), &product); err != nil {
+			return err
+		}
+		products[productIDs[index]] = product
+		return nil
+	})
+
+	return products
+}
+
+// SetProducts implements LookupCacheRepositoryInterface.
+func (l *lookupCacheRepository) SetProducts(ctx context.Context, products map[int64]entity.ProductResponseEntity) {
+	values := map[string]interface{}{}
+	for id, product := range products {
+		values[productCacheKey(id)] = product
+	}
+	l.set(ctx, values)
+}
+
+// DeleteProducts implements LookupCacheRepositoryInterface.
+func (l *lookupCacheRepository) DeleteProducts(ctx context.Context, productIDs []int64) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	if err := l.redisClient.Del(ctx, productCacheKeys(productIDs)...).Err(); err != nil {
+		log.Errorf("[LookupCacheRepository-1] DeleteProducts: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetCustomers implements LookupCacheRepositoryInterface.
+func (l *lookupCacheRepository) GetCustomers(ctx context.Context, customerIDs []int64) map[int64]entity.CustomerResponseEntity {
+	customers := map[int64]entity.CustomerResponseEntity{}
+	l.get(ctx, customerCacheKeys(customerIDs), func(index int, raw string) error {
+		var customer entity.CustomerResponseEntity
+		if err := json.Unmarshal([]byte(raw), &customer); err != nil {
+			return err
+		}
+		customers[customerIDs[index]] = customer
+		return nil
+	})
+
+	return customers
+}
+
+// SetCustomers implements LookupCacheRepositoryInterface.
+func (l *lookupCacheRepository) SetCustomers(ctx context.Context, customers map[int64]entity.CustomerResponseEntity) {
+	values := map[string]interface{}{}
+	for id, customer := range customers {
+		values[customerCacheKey(id)] = customer
+	}
+	l.set(ctx, values)
+}
+
+// DeleteCustomers implements LookupCacheRepositoryInterface.
+func (l *lookupCacheRepository) DeleteCustomers(ctx context.Context, customerIDs []int64) error {
+	if len(customerIDs) == 0 {
+		return nil
+	}
+
+	if err := l.redisClient.Del(ctx, customerCacheKeys(customerIDs)...).Err(); err != nil {
+		log.Errorf("[LookupCacheRepository-1] DeleteCustomers: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// get loads keys with a single MGET and hands every hit to decode together
+// with its position in keys.
+func (l *lookupCacheRepository) get(ctx context.Context, keys []string, decode func(index int, raw string) error) {
+	if len(keys) == 0 {
+		return
+	}
+
+	values, err := l.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		log.Errorf("[LookupCacheRepository-1] get: %v", err)
+		return
+	}
+
+	for index, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if err = decode(index, raw); err != nil {
+			log.Errorf("[LookupCacheRepository-2] get: %s: %v", keys[index], err)
+		}
+	}
+}
+
+// set stores every value as JSON with the cache TTL in one pipeline.
+func (l *lookupCacheRepository) set(ctx context.Context, values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	pipe := l.redisClient.Pipeline()
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			log.Errorf("[LookupCacheRepository-1] set: %s: %v", key, err)
+			continue
+		}
+		pipe.Set(ctx, key, data, l.ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Errorf("[LookupCacheRepository-2] set: %v", err)
+	}
+}
+
+func productCacheKey(productID int64) string {
+	return fmt.Sprintf("order-service:product:%d", productID)
+}
+
+func productCacheKeys(productIDs []int64) []string {
+	keys := []string{}
+	for _, id := range productIDs {
+		keys = append(keys, productCacheKey(id))
+	}
+
+	return keys
+}
+
+func customerCacheKey(customerID int64) string {
+	return fmt.Sprintf("order-service:customer:%d", customerID)
+}
+
+func customerCacheKeys(customerIDs []int64) []string {
+	keys := []string{}
+	for _, id := range customerIDs {
+		keys = append(keys, customerCacheKey(id))
+	}
+
+	return keys
+}
+
+func NewLookupCacheRepository(redisClient *redis.Client, cfg *config.Config) LookupCacheRepositoryInterface {
+	ttl := cfg.Redis.LookupCacheTTL
+	if ttl <= 0 {
+		ttl = defaultLookupCacheTTL
+	}
+
+	return &lookupCacheRepository{redisClient: redisClient, ttl: time.Duration(ttl) * time.Second}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"order-service/utils/esindex"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/labstack/gommon/log"
+)
+
+const OrderIndexName = "orders"
+
+// OrderIndexMapping defines the versioned indices behind the orders alias. Order
+// dates are kept as keywords because they are stored with and without a time.
+const OrderIndexMapping = `{
+	"mappings": {
+		"properties": {
+			"id": { "type": "long" },
+			"order_code": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+			"buyer_id": { "type": "long" },
+			"order_date": { "type": "keyword" },
+			"order_time": { "type": "keyword" },
+			"status": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+			"total_amount": { "type": "long" },
+			"payment_method": { "type": "keyword" },
+			"shipping_type": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+			"shipping_fee": { "type": "long" },
+			"remarks": { "type": "text" },
+			"created_at": { "type": "date" },
+			"buyer_name": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+			"buyer_email": { "type": "keyword" },
+			"buyer_phone": { "type": "keyword" },
+			"buyer_address": { "type": "text" },
+			"buyer_lat": { "type": "keyword" },
+			"buyer_lng": { "type": "keyword" },
+			"order_items": {
+				"properties": {
+					"id": { "type": "long" },
+					"order_id": { "type": "long" },
+					"product_id": { "type": "long" },
+					"quantity": { "type": "long" },
+					"order_code": { "type": "keyword" },
+					"product_name": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+					"product_image": { "type": "keyword", "index": false },
+					"price": { "type": "long" }
+				}
+			},
+			"status_histories": {
+				"properties": {
+					"id": { "type": "long" },
+					"order_id": { "type": "long" },
+					"from_status": { "type": "keyword" },
+					"to_status": { "type": "keyword" },
+					"note": { "type": "text" },
+					"changed_by": { "type": "long" },
+					"created_at": { "type": "date" }
+				}
+			}
+		}
+	}
+}`
+
+// EnsureOrderIndex creates a versioned index with OrderIndexMapping behind the
+// orders alias when neither exists yet. An existing alias or index is left as is.
+func EnsureOrderIndex(ctx context.Context, esClient *elasticsearch.Client) error {
+	exists, err := esindex.Exists(ctx, esClient, OrderIndexName)
+	if err != nil {
+		log.Errorf("[EnsureOrderIndex-1] %v", err)
+		return err
+	}
+
+	if exists {
+		log.Infof("[EnsureOrderIndex-2] Index %s already exists", OrderIndexName)
+		return nil
+	}
+
+	index := esindex.VersionedName(OrderIndexName)
+	if err = esindex.CreateIndex(ctx, esClient, index, OrderIndexMapping); err != nil {
+		log.Errorf("[EnsureOrderIndex-3] %v", err)
+		return err
+	}
+
+	if _, err = esindex.SwapAlias(ctx, esClient, OrderIndexName, index); err != nil {
+		log.Errorf("[EnsureOrderIndex-4] %v", err)
+		return err
+	}
+
+	log.Infof("[EnsureOrderIndex-5] Index %s created behind alias %s", index, OrderIndexName)
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"math"
 	"order-service/internal/core/domain/entity"
@@ -15,9 +16,12 @@ import (
 type OrderRepositoryInterface interface {
 	GetAll(ctx context.Context, queryString entity.QueryStringEntity) ([]entity.OrderEntity, int64, int64, error)
 	GetByID(ctx context.Context, orderID int64) (*entity.OrderEntity, error)
-	CreateOrder(ctx context.Context, req entity.OrderEntity) (int64, error)
+	CreateOrder(ctx context.Context, req entity.OrderEntity, events []entity.OutboxEntity) (int64, error)
 	EditOrder(ctx context.Context, req entity.OrderEntity) error
+	UpdateOrderStatus(ctx context.Context, req entity.OrderStatusHistoryEntity, events []entity.OutboxEntity) error
 	DeleteOrder(ctx context.Context, orderID int64) error
+	GetIndexDocuments(ctx context.Context, afterID int64, limit int, changedSince time.Time) ([]entity.OrderEntity, error)
+	CountIndexDocuments(ctx context.Context, afterID int64, changedSince time.Time) (int64, error)
 
 	GetAllPublished(ctx context.Context) ([]entity.OrderEntity, error)
 }
@@ -27,7 +31,10 @@ type orderRepository struct {
 }
 
 // CreateOrder implements OrderRepositoryInterface.
-func (o *orderRepository) CreateOrder(ctx context.Context, req entity.OrderEntity) (int64, error) {
+// The outbox events are stored in the same transaction as the order. Their
+// AggregateID is set to the new order ID, and events without a payload carry
+// the created order itself, including its first status history.
+func (o *orderRepository) CreateOrder(ctx context.Context, req entity.OrderEntity, events []entity.OutboxEntity) (int64, error) {
 	orderDate, err := time.Parse("2006-01-02", req.OrderDate)
 	if err != nil {
 		log.Errorf("[OrderRepository] CreateOrder: %v", err)
@@ -39,6 +46,7 @@ func (o *orderRepository) CreateOrder(ctx context.Context, req entity.OrderEntit
 		orderItem := model.OrderItem{
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
+			Price:     float64(item.Price),
 		}
 		orderItems = append(orderItems, orderItem)
 	}
@@ -54,9 +62,55 @@ func (o *orderRepository) CreateOrder(ctx context.Context, req entity.OrderEntit
 		ShippingFee:  float64(req.ShippingFee),
 		Remarks:      req.Remarks,
 		OrderItems:   orderItems,
+		StatusHistories: []model.OrderStatusHistory{
+			{
+				ToStatus:  req.Status,
+				ChangedBy: req.BuyerID,
+			},
+		},
 	}
 
-	if err := o.db.Create(&newOrder).Error; err != nil {
+	err = o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newOrder).Error; err != nil {
+			return err
+		}
+
+		req.ID = newOrder.ID
+		for key, item := range newOrder.OrderItems {
+			req.OrderItems[key].ID = item.ID
+			req.OrderItems[key].OrderID = newOrder.ID
+		}
+		for _, history := range newOrder.StatusHistories {
+			req.StatusHistories = append(req.StatusHistories, entity.OrderStatusHistoryEntity{
+				ID:        history.ID,
+				OrderID:   newOrder.ID,
+				ToStatus:  history.ToStatus,
+				ChangedBy: history.ChangedBy,
+				CreatedAt: history.CreatedAt,
+			})
+		}
+
+		for key := range events {
+			events[key].AggregateID = newOrder.ID
+			if events[key].Payload == "" {
+				payload, err := json.Marshal(req)
+				if err != nil {
+					return err
+				}
+				events[key].Payload = string(payload)
+			}
+		}
+
+		if len(events) > 0 {
+			modelOutbox := outboxEntitiesToModels(events)
+			if err := tx.Create(&modelOutbox).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
 		log.Errorf("[OrderRepository] CreateOrder: %v", err)
 		return 0, err
 	}
@@ -64,6 +118,84 @@ func (o *orderRepository) CreateOrder(ctx context.Context, req entity.OrderEntit
 	return newOrder.ID, nil
 }
 
+// UpdateOrderStatus implements OrderRepositoryInterface.
+// Order documents in the events get the ID of the new status history, which
+// the order consumer indexes them with as their version.
+func (o *orderRepository) UpdateOrderStatus(ctx context.Context, req entity.OrderStatusHistoryEntity, events []entity.OutboxEntity) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Order{}).
+			Where("id = ? AND status = ?", req.OrderID, req.FromStatus).
+			Updates(map[string]interface{}{"status": req.ToStatus, "updated_at": time.Now()})
+		if result.Error != nil {
+			log.Errorf("[OrderRepository-1] UpdateOrderStatus: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			err := errors.New("409")
+			log.Infof("[OrderRepository-2] UpdateOrderStatus: order %d is no longer %s", req.OrderID, req.FromStatus)
+			return err
+		}
+
+		history := model.OrderStatusHistory{
+			OrderID:    req.OrderID,
+			FromStatus: req.FromStatus,
+			ToStatus:   req.ToStatus,
+			Note:       req.Note,
+			ChangedBy:  req.ChangedBy,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			log.Errorf("[OrderRepository-3] UpdateOrderStatus: %v", err)
+			return err
+		}
+
+		if err := stampStatusHistory(events, history); err != nil {
+			log.Errorf("[OrderRepository-4] UpdateOrderStatus: %v", err)
+			return err
+		}
+
+		if len(events) > 0 {
+			modelOutbox := outboxEntitiesToModels(events)
+			if err := tx.Create(&modelOutbox).Error; err != nil {
+				log.Errorf("[OrderRepository-5] UpdateOrderStatus: %v", err)
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// stampStatusHistory sets the ID and time of the status history just created
+// on the history entries without an ID in the order documents of events.
+func stampStatusHistory(events []entity.OutboxEntity, history model.OrderStatusHistory) error {
+	for key, event := range events {
+		if event.EventType != entity.OutboxEventOrderIndexed {
+			continue
+		}
+
+		var order entity.OrderEntity
+		if err := json.Unmarshal([]byte(event.Payload), &order); err != nil {
+			return err
+		}
+
+		for key2, val := range order.StatusHistories {
+			if val.ID == 0 {
+				order.StatusHistories[key2].ID = history.ID
+				order.StatusHistories[key2].CreatedAt = history.CreatedAt
+			}
+		}
+
+		payload, err := json.Marshal(order)
+		if err != nil {
+			return err
+		}
+		events[key].Payload = string(payload)
+	}
+
+	return nil
+}
+
 // DeleteOrder implements OrderRepositoryInterface.
 func (o *orderRepository) DeleteOrder(ctx context.Context, orderID int64) error {
 	panic("unimplemented")
@@ -82,6 +214,10 @@ func (o *orderRepository) GetAll(ctx context.Context, queryString entity.QuerySt
 
 	sqlMain := o.db.Preload("OrderItems").
 		Where("order_code ILIKE ? OR status ILIKE ?", "%"+queryString.Search+"%", "%"+queryString.Status+"%")
+	if queryString.BuyerID > 0 {
+		sqlMain = sqlMain.Where("buyer_id = ?", queryString.BuyerID)
+	}
+
 	if err := sqlMain.Model(&modelOrders).Count(&countData).Error; err != nil {
 		log.Errorf("[OrderRepository-1] GetAll: %v", err)
 		return nil, 0, 0, err
@@ -107,6 +243,7 @@ func (o *orderRepository) GetAll(ctx context.Context, queryString entity.QuerySt
 				ID:        item.ID,
 				ProductID: item.ProductID,
 				Quantity:  item.Quantity,
+				Price:     int64(item.Price),
 			})
 		}
 		entities = append(entities, entity.OrderEntity{
@@ -123,6 +260,92 @@ func (o *orderRepository) GetAll(ctx context.Context, queryString entity.QuerySt
 	return entities, countData, int64(totalPage), nil
 }
 
+// GetIndexDocuments implements OrderRepositoryInterface.
+// It returns the next batch of orders after afterID with their items and status history.
+// A non-zero changedSince only returns orders created or updated since then.
+func (o *orderRepository) GetIndexDocuments(ctx context.Context, afterID int64, limit int, changedSince time.Time) ([]entity.OrderEntity, error) {
+	var modelOrders []model.Order
+
+	err := o.indexDocumentsQuery(ctx, afterID, changedSince).Preload("OrderItems").Preload("StatusHistories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Order("id ASC").
+		Limit(limit).
+		Find(&modelOrders).Error
+	if err != nil {
+		log.Errorf("[OrderRepository-1] GetIndexDocuments: %v", err)
+		return nil, err
+	}
+
+	entities := []entity.OrderEntity{}
+	for _, val := range modelOrders {
+		orderItemsEntities := []entity.OrderItemEntity{}
+		for _, item := range val.OrderItems {
+			orderItemsEntities = append(orderItemsEntities, entity.OrderItemEntity{
+				ID:        item.ID,
+				OrderID:   val.ID,
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				OrderCode: val.OrderCode,
+				Price:     int64(item.Price),
+			})
+		}
+
+		statusHistories := []entity.OrderStatusHistoryEntity{}
+		for _, history := range val.StatusHistories {
+			statusHistories = append(statusHistories, entity.OrderStatusHistoryEntity{
+				ID:         history.ID,
+				OrderID:    history.OrderID,
+				FromStatus: history.FromStatus,
+				ToStatus:   history.ToStatus,
+				Note:       history.Note,
+				ChangedBy:  history.ChangedBy,
+				CreatedAt:  history.CreatedAt,
+			})
+		}
+
+		entities = append(entities, entity.OrderEntity{
+			ID:              val.ID,
+			OrderCode:       val.OrderCode,
+			BuyerID:         val.BuyerID,
+			OrderDate:       val.OrderDate.Format("2006-01-02 15:04:05"),
+			Status:          val.Status,
+			TotalAmount:     int64(val.TotalAmount),
+			ShippingType:    val.ShippingType,
+			ShippingFee:     int64(val.ShippingFee),
+			OrderTime:       val.OrderTime,
+			Remarks:         val.Remarks,
+			CreatedAt:       val.CreatedAt,
+			OrderItems:      orderItemsEntities,
+			StatusHistories: statusHistories,
+		})
+	}
+
+	return entities, nil
+}
+
+// CountIndexDocuments implements OrderRepositoryInterface.
+func (o *orderRepository) CountIndexDocuments(ctx context.Context, afterID int64, changedSince time.Time) (int64, error) {
+	var count int64
+	err := o.indexDocumentsQuery(ctx, afterID, changedSince).Count(&count).Error
+	if err != nil {
+		log.Errorf("[OrderRepository-1] CountIndexDocuments: %v", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// indexDocumentsQuery selects the orders after afterID that GetIndexDocuments and CountIndexDocuments work on.
+func (o *orderRepository) indexDocumentsQuery(ctx context.Context, afterID int64, changedSince time.Time) *gorm.DB {
+	query := o.db.WithContext(ctx).Model(&model.Order{}).
+		Where("id > ? AND deleted_at IS NULL", afterID)
+	if !changedSince.IsZero() {
+		query = query.Where("(created_at >= ? OR updated_at >= ?)", changedSince, changedSince)
+	}
+
+	return query
+}
+
 // GetAllPublished implements OrderRepositoryInterface.
 func (o *orderRepository) GetAllPublished(ctx context.Context) ([]entity.OrderEntity, error) {
 	panic("unimplemented")
@@ -132,7 +355,9 @@ func (o *orderRepository) GetAllPublished(ctx context.Context) ([]entity.OrderEn
 func (o *orderRepository) GetByID(ctx context.Context, orderID int64) (*entity.OrderEntity, error) {
 	var modelOrders model.Order
 
-	if err := o.db.Preload("OrderItems").Where("id =?", orderID).First(&modelOrders).Error; err != nil {
+	if err := o.db.Preload("OrderItems").Preload("StatusHistories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("id =?", orderID).First(&modelOrders).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			err := errors.New("404")
 			log.Infof("[OrderRepository-1] GetByID: Order not found")
@@ -148,20 +373,35 @@ func (o *orderRepository) GetByID(ctx context.Context, orderID int64) (*entity.O
 			ID:        item.ID,
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
+			Price:     int64(item.Price),
+		})
+	}
+
+	statusHistories := []entity.OrderStatusHistoryEntity{}
+	for _, history := range modelOrders.StatusHistories {
+		statusHistories = append(statusHistories, entity.OrderStatusHistoryEntity{
+			ID:         history.ID,
+			OrderID:    history.OrderID,
+			FromStatus: history.FromStatus,
+			ToStatus:   history.ToStatus,
+			Note:       history.Note,
+			ChangedBy:  history.ChangedBy,
+			CreatedAt:  history.CreatedAt,
 		})
 	}
 
 	return &entity.OrderEntity{
-		ID:           modelOrders.ID,
-		OrderCode:    modelOrders.OrderCode,
-		Status:       modelOrders.Status,
-		BuyerID:      modelOrders.BuyerID,
-		OrderDate:    modelOrders.OrderDate.Format("2006-01-02 15:04:05"),
-		TotalAmount:  int64(modelOrders.TotalAmount),
-		OrderItems:   orderItemsEntities,
-		Remarks:      modelOrders.Remarks,
-		ShippingType: modelOrders.ShippingType,
-		ShippingFee:  int64(modelOrders.ShippingFee),
+		ID:              modelOrders.ID,
+		OrderCode:       modelOrders.OrderCode,
+		Status:          modelOrders.Status,
+		BuyerID:         modelOrders.BuyerID,
+		OrderDate:       modelOrders.OrderDate.Format("2006-01-02 15:04:05"),
+		TotalAmount:     int64(modelOrders.TotalAmount),
+		OrderItems:      orderItemsEntities,
+		Remarks:         modelOrders.Remarks,
+		ShippingType:    modelOrders.ShippingType,
+		ShippingFee:     int64(modelOrders.ShippingFee),
+		StatusHistories: statusHistories,
 	}, nil
 }
 
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"order-service/internal/core/domain/entity"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestOrderRepository(t *testing.T) (*orderRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	return &orderRepository{db: db}, mock
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	history := entity.OrderStatusHistoryEntity{
+		OrderID:    3,
+		FromStatus: entity.OrderStatusProcessing,
+		ToStatus:   entity.OrderStatusShipped,
+		Note:       "handed to courier",
+		ChangedBy:  1,
+	}
+
+	tests := []struct {
+		name string
+		// updated is how many orders still had the expected status.
+		updated int64
+		wantErr error
+	}{
+		{name: "order still has the expected status", updated: 1},
+		{name: "order status changed meanwhile", updated: 0, wantErr: errors.New("409")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newTestOrderRepository(t)
+
+			order := entity.OrderEntity{
+				ID:     history.OrderID,
+				Status: history.ToStatus,
+				StatusHistories: []entity.OrderStatusHistoryEntity{
+					{ID: 10, OrderID: history.OrderID, FromStatus: entity.OrderStatusConfirmed, ToStatus: entity.OrderStatusProcessing},
+					history,
+				},
+			}
+			payload, err := json.Marshal(order)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			events := []entity.OutboxEntity{{
+				AggregateType: "order",
+				AggregateID:   history.OrderID,
+				EventType:     entity.OutboxEventOrderIndexed,
+				Payload:       string(payload),
+			}}
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "orders" SET .* WHERE id = \$\d+ AND status = \$\d+`).
+				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), history.OrderID, history.FromStatus).
+				WillReturnResult(sqlmock.NewResult(0, tt.updated))
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectQuery(`INSERT INTO "order_status_histories"`).
+					WithArgs(history.OrderID, history.FromStatus, history.ToStatus, history.Note, history.ChangedBy, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+				mock.ExpectQuery(`INSERT INTO "outbox"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			}
+
+			err = repo.UpdateOrderStatus(context.Background(), history, events)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			var indexed entity.OrderEntity
+			if err := json.Unmarshal([]byte(events[0].Payload), &indexed); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if got := indexed.IndexVersion(); got != 11 {
+				t.Errorf("index version: got %d, want 11", got)
+			}
+			if indexed.StatusHistories[1].CreatedAt.IsZero() || time.Since(indexed.StatusHistories[1].CreatedAt) > time.Minute {
+				t.Errorf("new history created at %s, want now", indexed.StatusHistories[1].CreatedAt)
+			}
+		})
+	}
+}
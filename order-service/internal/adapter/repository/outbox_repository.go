@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"order-service/internal/core/domain/entity"
+	"order-service/internal/core/domain/model"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepositoryInterface interface {
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEntity, error)
+	MarkSent(ctx context.Context, outboxID int64) error
+	MarkFailed(ctx context.Context, req entity.OutboxEntity) error
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// ClaimPending implements OutboxRepositoryInterface.
+// Claimed rows are pushed forward by the lease so a second relay does not pick
+// them up while they are being published.
+func (o *outboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEntity, error) {
+	modelOutbox := []model.Outbox{}
+
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.OutboxStatusPending, time.Now()).
+			Order("id ASC").
+			Limit(limit).
+			Find(&modelOutbox).Error
+		if err != nil {
+			log.Errorf("[OutboxRepository-1] ClaimPending: %v", err)
+			return err
+		}
+
+		if len(modelOutbox) == 0 {
+			return nil
+		}
+
+		ids := []int64{}
+		for _, val := range modelOutbox {
+			ids = append(ids, val.ID)
+		}
+
+		err = tx.Model(&model.Outbox{}).Where("id IN ?", ids).Update("next_attempt_at", time.Now().Add(lease)).Error
+		if err != nil {
+			log.Errorf("[OutboxRepository-2] ClaimPending: %v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := []entity.OutboxEntity{}
+	for _, val := range modelOutbox {
+		entities = append(entities, entity.OutboxEntity{
+			ID:            val.ID,
+			AggregateType: val.AggregateType,
+			AggregateID:   val.AggregateID,
+			EventType:     val.EventType,
+			QueueName:     val.QueueName,
+			MessageID:     val.MessageID,
+			Payload:       val.Payload,
+			Status:        val.Status,
+			Attempts:      val.Attempts,
+			LastError:     val.LastError,
+			NextAttemptAt: val.NextAttemptAt,
+			CreatedAt:     val.CreatedAt,
+		})
+	}
+
+	return entities, nil
+}
+
+// MarkSent implements OutboxRepositoryInterface.
+func (o *outboxRepository) MarkSent(ctx context.Context, outboxID int64) error {
+	err := o.db.WithContext(ctx).Model(&model.Outbox{}).
+		Where("id = ?", outboxID).
+		Updates(map[string]interface{}{"status": entity.OutboxStatusSent, "sent_at": time.Now()}).Error
+	if err != nil {
+		log.Errorf("[OutboxRepository-1] MarkSent: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed implements OutboxRepositoryInterface.
+func (o *outboxRepository) MarkFailed(ctx context.Context, req entity.OutboxEntity) error {
+	err := o.db.WithContext(ctx).Model(&model.Outbox{}).
+		Where("id = ?", req.ID).
+		Updates(map[string]interface{}{
+			"status":          req.Status,
+			"attempts":        req.Attempts,
+			"last_error":      req.LastError,
+			"next_attempt_at": req.NextAttemptAt,
+		}).Error
+	if err != nil {
+		log.Errorf("[OutboxRepository-1] MarkFailed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func outboxEntitiesToModels(events []entity.OutboxEntity) []model.Outbox {
+	modelOutbox := []model.Outbox{}
+	for _, val := range events {
+		modelOutbox = append(modelOutbox, model.Outbox{
+			AggregateType: val.AggregateType,
+			AggregateID:   val.AggregateID,
+			EventType:     val.EventType,
+			QueueName:     val.QueueName,
+			MessageID:     val.MessageID,
+			Payload:       val.Payload,
+			Status:        entity.OutboxStatusPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	return modelOutbox
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepositoryInterface {
+	return &outboxRepository{db: db}
+}
@@ -6,7 +6,6 @@ import (
 	"order-service/config"
 	"order-service/internal/adapter/handlers"
 	httpclient "order-service/internal/adapter/http_client"
-	"order-service/internal/adapter/message"
 	"order-service/internal/adapter/repository"
 	"order-service/internal/core/service"
 	"order-service/utils/validator"
@@ -28,21 +27,25 @@ func RunServer() {
 		return
 	}
 
-	// elasticInit, err := cfg.InitElasticsearch()
-	// if err != nil {
-	// 	log.Fatalf("[RunServer-2] %v", err)
-	// 	return
-	// }
+	elasticInit, err := cfg.InitElasticsearch()
+	if err != nil {
+		log.Fatalf("[RunServer-2] %v", err)
+		return
+	}
 
 	// storageHandler := storage.NewSupabase(cfg)
 
 	orderRepo := repository.NewOrderRepository(db.DB)
+	elasticRepo := repository.NewElasticRepository(elasticInit, cfg)
+	if err = elasticRepo.Ping(context.Background()); err != nil {
+		log.Printf("[RunServer-3] Elasticsearch unavailable, order lists are served from Postgres: %v", err)
+	}
 
 	httpClient := httpclient.NewHttpClient(cfg)
 
-	messageRabbit := message.NewPublisherRabbitMQ(cfg)
+	lookupCache := repository.NewLookupCacheRepository(cfg.NewRedisClient(), cfg)
 
-	orderService := service.NewOrderService(orderRepo, cfg, httpClient, messageRabbit)
+	orderService := service.NewOrderService(orderRepo, cfg, httpClient, elasticRepo, lookupCache)
 
 	e := echo.New()
 	e.Use(middleware.CORS())
@@ -64,7 +67,7 @@ func RunServer() {
 
 		err = e.Start(":" + cfg.App.AppPort)
 		if err != nil {
-			log.Fatalf("[RunServer-2] %v", err)
+			log.Fatalf("[RunServer-4] %v", err)
 		}
 	}()
 
@@ -74,7 +77,7 @@ func RunServer() {
 
 	<-quit
 
-	log.Print("[RunServer-3] Shutting down server of 5 second...")
+	log.Print("[RunServer-5] Shutting down server of 5 second...")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"log"
+	"order-service/config"
+	httpclient "order-service/internal/adapter/http_client"
+	"order-service/internal/adapter/repository"
+	"order-service/internal/core/service"
+	"order-service/utils/esindex"
+	"strconv"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"gorm.io/gorm"
+)
+
+// rebuildCatchUpMargin is subtracted from the catch-up start times so a small
+// clock difference between this process and Postgres cannot skip a change.
+const rebuildCatchUpMargin = time.Minute
+
+// RebuildOrderIndex loads every order from Postgres into a new versioned index
+// and then points the orders alias at it, so searches keep using the old index
+// until the new one is complete.
+//
+// The order worker keeps writing to the alias, that is to the old index, while
+// the batches load. Orders changed since the load started are therefore indexed
+// again before the swap, and once more after it for changes made during that
+// catch-up.
+func RebuildOrderIndex(batchSize int, keepOld bool) error {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Printf("[RebuildOrderIndex-1] %v", err)
+		return err
+	}
+
+	esClient, err := cfg.InitElasticsearch()
+	if err != nil {
+		log.Printf("[RebuildOrderIndex-2] %v", err)
+		return err
+	}
+
+	orderService := newOrderIndexService(cfg, db.DB, esClient)
+
+	index := esindex.VersionedName(repository.OrderIndexName)
+	if err = esindex.CreateIndex(ctx, esClient, index, repository.OrderIndexMapping); err != nil {
+		log.Printf("[RebuildOrderIndex-3] %v", err)
+		return err
+	}
+	log.Printf("[RebuildOrderIndex-4] Created index %s", index)
+
+	loadStarted := time.Now().Add(-rebuildCatchUpMargin)
+	if _, err = indexOrderBatches(ctx, orderService, esClient, index, 0, batchSize, time.Time{}); err != nil {
+		log.Printf("[RebuildOrderIndex-5] %v", err)
+		return err
+	}
+
+	catchUpStarted := time.Now().Add(-rebuildCatchUpMargin)
+	if _, err = indexOrderBatches(ctx, orderService, esClient, index, 0, batchSize, loadStarted); err != nil {
+		log.Printf("[RebuildOrderIndex-6] %v", err)
+		return err
+	}
+
+	previous, err := esindex.SwapAlias(ctx, esClient, repository.OrderIndexName, index)
+	if err != nil {
+		log.Printf("[RebuildOrderIndex-7] %v", err)
+		return err
+	}
+	log.Printf("[RebuildOrderIndex-8] Alias %s now points to %s", repository.OrderIndexName, index)
+
+	if _, err = indexOrderBatches(ctx, orderService, esClient, index, 0, batchSize, catchUpStarted); err != nil {
+		log.Printf("[RebuildOrderIndex-9] %v", err)
+		return err
+	}
+
+	if keepOld {
+		return nil
+	}
+
+	if err = esindex.DeleteIndices(ctx, esClient, previous); err != nil {
+		log.Printf("[RebuildOrderIndex-10] %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReindexOrders streams orders with an ID greater than afterID into the live
+// orders alias. When a run stops halfway, pass the last ID it reported to
+// resume from there.
+func ReindexOrders(afterID int64, batchSize int) error {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Printf("[ReindexOrders-1] %v", err)
+		return err
+	}
+
+	esClient, err := cfg.InitElasticsearch()
+	if err != nil {
+		log.Printf("[ReindexOrders-2] %v", err)
+		return err
+	}
+
+	orderService := newOrderIndexService(cfg, db.DB, esClient)
+
+	if err = repository.EnsureOrderIndex(ctx, esClient); err != nil {
+		log.Printf("[ReindexOrders-3] %v", err)
+		return err
+	}
+
+	lastID, err := indexOrderBatches(ctx, orderService, esClient, repository.OrderIndexName, afterID, batchSize, time.Time{})
+	if err != nil {
+		log.Printf("[ReindexOrders-4] Stopped after id %d, rerun with --after-id %d to resume: %v", lastID, lastID, err)
+		return err
+	}
+
+	log.Printf("[ReindexOrders-5] Finished, last id %d", lastID)
+	return nil
+}
+
+func newOrderIndexService(cfg *config.Config, db *gorm.DB, esClient *elasticsearch.Client) service.OrderServiceInterface {
+	return service.NewOrderService(
+		repository.NewOrderRepository(db),
+		cfg,
+		httpclient.NewHttpClient(cfg),
+		repository.NewElasticRepository(esClient, cfg),
+		repository.NewLookupCacheRepository(cfg.NewRedisClient(), cfg),
+	)
+}
+
+// indexOrderBatches bulk indexes orders after afterID into index one batch at a
+// time, logging progress against the number of orders left at the start. A
+// non-zero changedSince only indexes the orders changed since then. It returns
+// the ID of the last order that was written.
+func indexOrderBatches(ctx context.Context, orderService service.OrderServiceInterface, esClient *elasticsearch.Client, index string, afterID int64, batchSize int, changedSince time.Time) (int64, error) {
+	remaining, err := orderService.CountIndexDocuments(ctx, afterID, changedSince)
+	if err != nil {
+		log.Printf("[indexOrderBatches-1] %v", err)
+		return afterID, err
+	}
+	log.Printf("[indexOrderBatches-2] %d orders to index after id %d", remaining, afterID)
+
+	var total int64
+	for {
+		orders, err := orderService.GetIndexDocuments(ctx, afterID, batchSize, changedSince)
+		if err != nil {
+			log.Printf("[indexOrderBatches-3] %v", err)
+			return afterID, err
+		}
+
+		if len(orders) == 0 {
+			break
+		}
+
+		docs := []esindex.Document{}
+		for _, val := range orders {
+			docs = append(docs, esindex.Document{ID: strconv.FormatInt(val.ID, 10), Body: val, Version: val.IndexVersion()})
+		}
+
+		if err = esindex.Bulk(ctx, esClient, index, docs); err != nil {
+			log.Printf("[indexOrderBatches-4] %v", err)
+			return afterID, err
+		}
+
+		total += int64(len(orders))
+		afterID = orders[len(orders)-1].ID
+		log.Printf("[indexOrderBatches-5] Indexed %d/%d orders (last id %d)", total, remaining, afterID)
+	}
+
+	return afterID, nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"log"
+	"order-service/config"
+	"order-service/internal/adapter/message"
+	"order-service/internal/adapter/repository"
+	"order-service/internal/core/service"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// RunOutboxRelay periodically publishes order events that were committed to the
+// outbox table together with their order changes.
+func RunOutboxRelay(interval time.Duration, batchSize int) {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatalf("[RunOutboxRelay-1] %v", err)
+		return
+	}
+
+	rabbitPublisher := message.NewRabbitMQPublisher(cfg)
+	defer rabbitPublisher.Close()
+
+	outboxService := service.NewOutboxService(repository.NewOutboxRepository(db.DB), cfg, message.NewPublisherRabbitMQ(cfg, rabbitPublisher))
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, syscall.SIGTERM)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sent, err := outboxService.RelayPending(context.Background(), batchSize)
+			if err != nil {
+				log.Printf("[RunOutboxRelay-2] %v", err)
+				continue
+			}
+
+			if sent > 0 {
+				log.Printf("[RunOutboxRelay-3] Published %d outbox events", sent)
+			}
+		case <-quit:
+			log.Print("[RunOutboxRelay-4] Stopping outbox relay...")
+			return
+		}
+	}
+}
@@ -5,6 +5,11 @@ type UserHttpClientResponse struct {
 	Data    CustomerResponseEntity `json:"data"`
 }
 
+type UsersHttpClientResponse struct {
+	Message string                   `json:"message"`
+	Data    []CustomerResponseEntity `json:"data"`
+}
+
 type CustomerResponseEntity struct {
 	RoleID  int    `json:"role_id"`
 	ID      int    `json:"id"`
@@ -16,3 +21,8 @@ type CustomerResponseEntity struct {
 	Address string `json:"address"`
 	Photo   string `json:"photo"`
 }
+
+// CustomerChangedEntity is published by user-service whenever a customer changes.
+type CustomerChangedEntity struct {
+	CustomerID int64 `json:"customer_id"`
+}
@@ -3,25 +3,40 @@ package entity
 import "time"
 
 type OrderEntity struct {
-	ID            int64
-	OrderCode     string
-	BuyerID       int64
-	OrderDate     string
-	Status        string
-	TotalAmount   int64
-	PaymentMethod string
-	ShippingType  string
-	ShippingFee   int64
-	OrderTime     string
-	Remarks       string
-	CreatedAt     time.Time
-	OrderItems    []OrderItemEntity
-	BuyerName     string
-	BuyerEmail    string
-	BuyerPhone    string
-	BuyerAddress  string
-	BuyerLat      string
-	BuyerLng      string
+	ID              int64                      `json:"id"`
+	OrderCode       string                     `json:"order_code"`
+	BuyerID         int64                      `json:"buyer_id"`
+	OrderDate       string                     `json:"order_date"`
+	Status          string                     `json:"status"`
+	TotalAmount     int64                      `json:"total_amount"`
+	PaymentMethod   string                     `json:"payment_method"`
+	ShippingType    string                     `json:"shipping_type"`
+	ShippingFee     int64                      `json:"shipping_fee"`
+	OrderTime       string                     `json:"order_time"`
+	Remarks         string                     `json:"remarks"`
+	CreatedAt       time.Time                  `json:"created_at"`
+	OrderItems      []OrderItemEntity          `json:"order_items"`
+	BuyerName       string                     `json:"buyer_name"`
+	BuyerEmail      string                     `json:"buyer_email"`
+	BuyerPhone      string                     `json:"buyer_phone"`
+	BuyerAddress    string                     `json:"buyer_address"`
+	BuyerLat        string                     `json:"buyer_lat"`
+	BuyerLng        string                     `json:"buyer_lng"`
+	StatusHistories []OrderStatusHistoryEntity `json:"status_histories"`
+}
+
+// IndexVersion returns the ID of the latest status history of the order. Every
+// status change adds a history row, so it orders the search documents of one
+// order; it is 0 when no history with an ID is loaded.
+func (o OrderEntity) IndexVersion() int64 {
+	var version int64
+	for _, history := range o.StatusHistories {
+		if history.ID > version {
+			version = history.ID
+		}
+	}
+
+	return version
 }
 
 type QueryStringEntity struct {
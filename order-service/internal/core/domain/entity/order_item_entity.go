@@ -1,17 +1,23 @@
 package entity
 
 type OrderItemEntity struct {
-	ID           int64
-	OrderID      int64
-	ProductID    int64
-	Quantity     int64
-	OrderCode    string
-	ProductName  string
-	ProductImage string
-	Price        int64
+	ID           int64  `json:"id"`
+	OrderID      int64  `json:"order_id"`
+	ProductID    int64  `json:"product_id"`
+	Quantity     int64  `json:"quantity"`
+	OrderCode    string `json:"order_code"`
+	ProductName  string `json:"product_name"`
+	ProductImage string `json:"product_image"`
+	Price        int64  `json:"price"`
 }
 
 type PublishOrderItemEntity struct {
 	ProductID int64 `json:"product_id"`
 	Quantity  int64 `json:"quantity"`
 }
+
+type PublishRestockEntity struct {
+	EventKey string                   `json:"event_key"`
+	OrderID  int64                    `json:"order_id"`
+	Items    []PublishOrderItemEntity `json:"items"`
+}
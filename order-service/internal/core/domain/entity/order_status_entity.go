@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+const (
+	OrderStatusPending        = "Pending"
+	OrderStatusConfirmed      = "Confirmed"
+	OrderStatusProcessing     = "Processing"
+	OrderStatusShipped        = "Shipped"
+	OrderStatusReadyForPickup = "ReadyForPickup"
+	OrderStatusCompleted      = "Completed"
+	OrderStatusCancelled      = "Cancelled"
+)
+
+// orderStatusTransitions lists the statuses an order may move to from its current status.
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPending:        {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed:      {OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusProcessing:     {OrderStatusShipped, OrderStatusReadyForPickup, OrderStatusCancelled},
+	OrderStatusShipped:        {OrderStatusCompleted},
+	OrderStatusReadyForPickup: {OrderStatusCompleted},
+}
+
+func CanTransitionOrderStatus(from, to string) bool {
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type OrderStatusHistoryEntity struct {
+	ID         int64     `json:"id"`
+	OrderID    int64     `json:"order_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Note       string    `json:"note"`
+	ChangedBy  int64     `json:"changed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "testing"
+
+func TestCanTransitionOrderStatus(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{from: OrderStatusPending, to: OrderStatusConfirmed, want: true},
+		{from: OrderStatusPending, to: OrderStatusCancelled, want: true},
+		{from: OrderStatusPending, to: OrderStatusProcessing, want: false},
+		{from: OrderStatusPending, to: OrderStatusCompleted, want: false},
+		{from: OrderStatusConfirmed, to: OrderStatusProcessing, want: true},
+		{from: OrderStatusConfirmed, to: OrderStatusCancelled, want: true},
+		{from: OrderStatusConfirmed, to: OrderStatusPending, want: false},
+		{from: OrderStatusConfirmed, to: OrderStatusShipped, want: false},
+		{from: OrderStatusProcessing, to: OrderStatusShipped, want: true},
+		{from: OrderStatusProcessing, to: OrderStatusReadyForPickup, want: true},
+		{from: OrderStatusProcessing, to: OrderStatusCancelled, want: true},
+		{from: OrderStatusProcessing, to: OrderStatusCompleted, want: false},
+		{from: OrderStatusShipped, to: OrderStatusCompleted, want: true},
+		{from: OrderStatusShipped, to: OrderStatusCancelled, want: false},
+		{from: OrderStatusReadyForPickup, to: OrderStatusCompleted, want: true},
+		{from: OrderStatusReadyForPickup, to: OrderStatusShipped, want: false},
+		{from: OrderStatusCompleted, to: OrderStatusCancelled, want: false},
+		{from: OrderStatusCancelled, to: OrderStatusPending, want: false},
+		{from: OrderStatusPending, to: OrderStatusPending, want: false},
+		{from: OrderStatusPending, to: "Unknown", want: false},
+		{from: "Unknown", to: OrderStatusConfirmed, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			if got := CanTransitionOrderStatus(tt.from, tt.to); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	OutboxStatusPending = "PENDING"
+	OutboxStatusSent    = "SENT"
+	OutboxStatusFailed  = "FAILED"
+
+	OutboxEventOrderIndexed = "order.indexed"
+	OutboxEventOrderRestock = "order.restock"
+)
+
+type OutboxEntity struct {
+	ID            int64
+	AggregateType string
+	AggregateID   int64
+	EventType     string
+	QueueName     string
+	MessageID     string
+	Payload       string
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
@@ -2,11 +2,19 @@ package entity
 
 import "time"
 
+// ProductStatusActive is the status of products that can be ordered.
+const ProductStatusActive = "ACTIVE"
+
 type ProductHttpClientResponse struct {
 	Message string                `json:"message"`
 	Data    ProductResponseEntity `json:"data"`
 }
 
+type ProductsHttpClientResponse struct {
+	Message string                  `json:"message"`
+	Data    []ProductResponseEntity `json:"data"`
+}
+
 type ChildProductResponseEntity struct {
 	ID           int     `json:"id"`
 	Weight       int     `json:"weight"`
@@ -30,3 +38,8 @@ type ProductResponseEntity struct {
 	Stock         int                          `json:"stock"`
 	Child         []ChildProductResponseEntity `json:"child"`
 }
+
+// ProductChangedEntity is published by product-service whenever products change.
+type ProductChangedEntity struct {
+	ProductIDs []int64 `json:"product_ids"`
+}
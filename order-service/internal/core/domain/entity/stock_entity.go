@@ -0,0 +1,27 @@
+package entity
+
+type StockReservationRequestEntity struct {
+	ReservationCode string                   `json:"reservation_code"`
+	Items           []PublishOrderItemEntity `json:"items"`
+}
+
+type StockShortageHttpClientResponse struct {
+	Message string                `json:"message"`
+	Data    []StockShortageEntity `json:"data"`
+}
+
+type StockShortageEntity struct {
+	ProductID int64 `json:"product_id"`
+	Requested int64 `json:"requested"`
+	Available int64 `json:"available"`
+}
+
+// InsufficientStockError is returned when product-service rejects a reservation
+// because one or more order lines exceed the available stock.
+type InsufficientStockError struct {
+	Items []StockShortageEntity
+}
+
+func (e *InsufficientStockError) Error() string {
+	return "409"
+}
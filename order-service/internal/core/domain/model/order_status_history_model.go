@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type OrderStatusHistory struct {
+	ID         int64  `gorm:"primaryKey"`
+	OrderID    int64  `gorm:"order_id"`
+	FromStatus string `gorm:"from_status"`
+	ToStatus   string `gorm:"to_status"`
+	Note       string `gorm:"note"`
+	ChangedBy  int64  `gorm:"changed_by"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type Outbox struct {
+	ID            int64  `gorm:"primaryKey"`
+	AggregateType string `gorm:"aggregate_type"`
+	AggregateID   int64  `gorm:"aggregate_id"`
+	EventType     string `gorm:"event_type"`
+	QueueName     string `gorm:"queue_name"`
+	MessageID     string `gorm:"message_id"`
+	Payload       string `gorm:"payload"`
+	Status        string `gorm:"status"`
+	Attempts      int    `gorm:"attempts"`
+	LastError     string `gorm:"last_error"`
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+func (Outbox) TableName() string {
+	return "outbox"
+}
@@ -3,31 +3,50 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"math"
+	"net/http"
 	"order-service/config"
 	httpclient "order-service/internal/adapter/http_client"
-	"order-service/internal/adapter/message"
 	"order-service/internal/adapter/repository"
 	"order-service/internal/core/domain/entity"
+	"order-service/utils/breaker"
 	"order-service/utils/conv"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/labstack/gommon/log"
 )
 
+// batchLookupSize matches the maximum number of IDs accepted by the
+// user-service and product-service ?ids= endpoints.
+const batchLookupSize = 100
+
 type OrderServiceInterface interface {
 	GetAll(ctx context.Context, queryString entity.QueryStringEntity, accessToken string) ([]entity.OrderEntity, int64, int64, error)
 	GetByID(ctx context.Context, orderID int64, accessToken string) (*entity.OrderEntity, error)
 	CreateOrder(ctx context.Context, req entity.OrderEntity, accessToken string) (int64, error)
+	UpdateStatus(ctx context.Context, orderID int64, status, note string, accessToken string) error
+
+	// Modul Orders Customer
+	GetAllCustomer(ctx context.Context, queryString entity.QueryStringEntity, accessToken string) ([]entity.OrderEntity, int64, int64, error)
+	GetByIDCustomer(ctx context.Context, orderID int64, accessToken string) (*entity.OrderEntity, error)
+	CancelOrder(ctx context.Context, orderID int64, accessToken string) error
+
+	// Modul Orders Index
+	GetIndexDocuments(ctx context.Context, afterID int64, limit int, changedSince time.Time) ([]entity.OrderEntity, error)
+	CountIndexDocuments(ctx context.Context, afterID int64, changedSince time.Time) (int64, error)
 }
 
 type orderService struct {
-	repo              repository.OrderRepositoryInterface
-	cfg               *config.Config
-	httpClient        httpclient.HttpClient
-	publisherRabbitMQ message.PublishRabbitMQInterface
-	elasticRepo       repository.ElasticRepositoryInterface
+	repo        repository.OrderRepositoryInterface
+	cfg         *config.Config
+	httpClient  httpclient.HttpClient
+	elasticRepo repository.ElasticRepositoryInterface
+	lookupCache repository.LookupCacheRepositoryInterface
 }
 
 // CreateOrder implements OrderServiceInterface.
@@ -40,64 +59,466 @@ func (o *orderService) CreateOrder(ctx context.Context, req entity.OrderEntity,
 	req.ShippingFee = int64(shippingFee)
 	req.Status = "Pending"
 
-	orderID, err := o.repo.CreateOrder(ctx, req)
+	totalAmount, err := o.calculateOrderPrice(ctx, req.OrderItems)
 	if err != nil {
 		log.Errorf("[OrderService-1] CreateOrder: %v", err)
 		return 0, err
 	}
 
-	resultData, err := o.GetByID(ctx, orderID, accessToken)
-	if err != nil {
-		log.Errorf("[OrderService-2] CreateOrder: %v", err)
+	totalAmount += req.ShippingFee
+	if req.TotalAmount > 0 && req.TotalAmount != totalAmount {
+		err = errors.New("422")
+		log.Errorf("[OrderService-2] CreateOrder: total amount %d does not match calculated %d", req.TotalAmount, totalAmount)
+		return 0, err
 	}
+	req.TotalAmount = totalAmount
 
-	if err := o.publisherRabbitMQ.PublishOrderToQueue(*resultData); err != nil {
+	var userData entity.JwtUserData
+	if err = json.Unmarshal([]byte(accessToken), &userData); err != nil {
 		log.Errorf("[OrderService-3] CreateOrder: %v", err)
+		return 0, err
+	}
+	req.BuyerID = userData.UserID
+	req.BuyerName = userData.Name
+	req.BuyerEmail = userData.Email
+
+	if err = o.httpClientReserveStock(ctx, req.OrderCode, req.OrderItems); err != nil {
+		log.Errorf("[OrderService-4] CreateOrder: %v", err)
+		return 0, err
+	}
+
+	// The payload is left empty so the repository fills it with the order once the ID is known.
+	events := []entity.OutboxEntity{
+		{
+			AggregateType: "order",
+			EventType:     entity.OutboxEventOrderIndexed,
+			QueueName:     o.cfg.PublisherName.OrderPublish,
+		},
 	}
 
-	for _, orderItem := range req.OrderItems {
-		o.publisherRabbitMQ.PublishUpdateStock(orderItem.ProductID, orderItem.Quantity)
+	orderID, err := o.repo.CreateOrder(ctx, req, events)
+	if err != nil {
+		log.Errorf("[OrderService-5] CreateOrder: %v", err)
+		if errRelease := o.httpClientUpdateReservation(context.WithoutCancel(ctx), req.OrderCode, "release"); errRelease != nil {
+			log.Errorf("[OrderService-6] CreateOrder: %v", errRelease)
+		}
+		return 0, err
+	}
+
+	if err = o.httpClientUpdateReservation(ctx, req.OrderCode, "confirm"); err != nil {
+		log.Errorf("[OrderService-7] CreateOrder: %v", err)
+		req.ID = orderID
+		if errCancel := o.cancelUnconfirmedOrder(context.WithoutCancel(ctx), req, userData.UserID); errCancel != nil {
+			log.Errorf("[OrderService-8] CreateOrder: %v", errCancel)
+		}
+		return 0, err
 	}
 
 	return orderID, nil
 }
 
-// GetByID implements OrderServiceInterface.
-func (o *orderService) GetByID(ctx context.Context, orderID int64, accessToken string) (*entity.OrderEntity, error) {
+// cancelUnconfirmedOrder cancels an order whose stock reservation could not be confirmed.
+// The confirm may have been committed by product-service even though the call failed, so the
+// reservation is released first. When product-service reports it as already confirmed, the stock
+// is restored with the same restock event a customer cancellation writes.
+func (o *orderService) cancelUnconfirmedOrder(ctx context.Context, order entity.OrderEntity, changedBy int64) error {
+	history := entity.OrderStatusHistoryEntity{
+		OrderID:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   entity.OrderStatusCancelled,
+		Note:       "Stock reservation could not be confirmed",
+		ChangedBy:  changedBy,
+	}
+
+	var events []entity.OutboxEntity
+	err := o.httpClientUpdateReservation(ctx, order.OrderCode, "release")
+	switch {
+	case err != nil && err.Error() == "409":
+		events, err = o.statusChangeEvents(order, history)
+		if err != nil {
+			log.Errorf("[OrderService-1] cancelUnconfirmedOrder: %v", err)
+			return err
+		}
+	default:
+		if err != nil && err.Error() != "404" {
+			log.Errorf("[OrderService-2] cancelUnconfirmedOrder: reservation %s could not be released, its stock has to be checked: %v", order.OrderCode, err)
+		}
+
+		event, err := o.orderIndexEvent(order, history)
+		if err != nil {
+			log.Errorf("[OrderService-3] cancelUnconfirmedOrder: %v", err)
+			return err
+		}
+		events = []entity.OutboxEntity{event}
+	}
+
+	if err = o.repo.UpdateOrderStatus(ctx, history, events); err != nil {
+		log.Errorf("[OrderService-4] cancelUnconfirmedOrder: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByIDCustomer implements OrderServiceInterface.
+func (o *orderService) GetByIDCustomer(ctx context.Context, orderID int64, accessToken string) (*entity.OrderEntity, error) {
+	var userData entity.JwtUserData
+	if err := json.Unmarshal([]byte(accessToken), &userData); err != nil {
+		log.Errorf("[OrderService-1] GetByIDCustomer: %v", err)
+		return nil, err
+	}
+
 	result, err := o.repo.GetByID(ctx, orderID)
 	if err != nil {
-		log.Errorf("[OrderService-1] GetByID: %v", err)
+		log.Errorf("[OrderService-2] GetByIDCustomer: %v", err)
 		return nil, err
 	}
 
-	var token map[string]interface{}
-	err = json.Unmarshal([]byte(accessToken), &token)
+	if result.BuyerID != userData.UserID {
+		err = errors.New("404")
+		log.Infof("[OrderService-3] GetByIDCustomer: order %d does not belong to user %d", orderID, userData.UserID)
+		return nil, err
+	}
+
+	productIDs := []int64{}
+	for _, item := range result.OrderItems {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := o.lookupProducts(ctx, productIDs)
 	if err != nil {
-		log.Errorf("[OrderService-2] GetByID: %v", err)
+		log.Errorf("[OrderService-4] GetByIDCustomer: %v", err)
+		return nil, err
+	}
+
+	result.BuyerName = userData.Name
+	result.BuyerEmail = userData.Email
+	for key, val := range result.OrderItems {
+		productResponse := products[val.ProductID]
+		result.OrderItems[key].ProductImage = productResponse.ProductImage
+		result.OrderItems[key].ProductName = productResponse.ProductName
+		if val.Price == 0 {
+			result.OrderItems[key].Price = int64(math.Round(productResponse.SalePrice))
+		}
+	}
+
+	return result, nil
+}
+
+// GetAllCustomer implements OrderServiceInterface.
+func (o *orderService) GetAllCustomer(ctx context.Context, queryString entity.QueryStringEntity, accessToken string) ([]entity.OrderEntity, int64, int64, error) {
+	var userData entity.JwtUserData
+	if err := json.Unmarshal([]byte(accessToken), &userData); err != nil {
+		log.Errorf("[OrderService-1] GetAllCustomer: %v", err)
+		return nil, 0, 0, err
+	}
+
+	if userData.UserID == 0 {
+		err := errors.New("401")
+		log.Errorf("[OrderService-2] GetAllCustomer: %v", err)
+		return nil, 0, 0, err
+	}
+	queryString.BuyerID = userData.UserID
+
+	results, count, total, err := o.elasticRepo.SearchOrderElastic(ctx, queryString)
+	if err == nil {
+		return results, count, total, nil
+	}
+	if !errors.Is(err, breaker.ErrOpen) {
+		log.Errorf("[OrderService-3] GetAllCustomer: %v", err)
+	}
+
+	results, count, total, err = o.repo.GetAll(ctx, queryString)
+	if err != nil {
+		log.Errorf("[OrderService-4] GetAllCustomer: %v", err)
+		return nil, 0, 0, err
+	}
+
+	productIDs := []int64{}
+	seenProducts := map[int64]bool{}
+	for _, val := range results {
+		for _, item := range val.OrderItems {
+			if !seenProducts[item.ProductID] {
+				seenProducts[item.ProductID] = true
+				productIDs = append(productIDs, item.ProductID)
+			}
+		}
+	}
+	products, err := o.lookupProducts(ctx, productIDs)
+	if err != nil {
+		log.Errorf("[OrderService-5] GetAllCustomer: %v", err)
+		return nil, 0, 0, err
+	}
+
+	for key, val := range results {
+		results[key].BuyerName = userData.Name
+		for key2, res := range val.OrderItems {
+			productResponse := products[res.ProductID]
+			results[key].OrderItems[key2].ProductImage = productResponse.ProductImage
+			results[key].OrderItems[key2].ProductName = productResponse.ProductName
+		}
+	}
+
+	return results, count, total, nil
+}
+
+// UpdateStatus implements OrderServiceInterface.
+func (o *orderService) UpdateStatus(ctx context.Context, orderID int64, status, note string, accessToken string) error {
+	order, err := o.repo.GetByID(ctx, orderID)
+	if err != nil {
+		log.Errorf("[OrderService-1] UpdateStatus: %v", err)
+		return err
+	}
+
+	if !entity.CanTransitionOrderStatus(order.Status, status) {
+		err = errors.New("422")
+		log.Errorf("[OrderService-2] UpdateStatus: cannot move order %d from %s to %s", orderID, order.Status, status)
+		return err
+	}
+
+	if (status == entity.OrderStatusShipped && order.ShippingType != "Delivery") ||
+		(status == entity.OrderStatusReadyForPickup && order.ShippingType == "Delivery") {
+		err = errors.New("422")
+		log.Errorf("[OrderService-3] UpdateStatus: status %s does not apply to shipping type %s", status, order.ShippingType)
+		return err
+	}
+
+	var userData entity.JwtUserData
+	if err = json.Unmarshal([]byte(accessToken), &userData); err != nil {
+		log.Errorf("[OrderService-4] UpdateStatus: %v", err)
+		return err
+	}
+
+	history := entity.OrderStatusHistoryEntity{
+		OrderID:    orderID,
+		FromStatus: order.Status,
+		ToStatus:   status,
+		Note:       note,
+		ChangedBy:  userData.UserID,
+	}
+
+	resultData, err := o.GetByID(ctx, orderID, accessToken)
+	if err != nil {
+		log.Errorf("[OrderService-5] UpdateStatus: %v", err)
+		resultData = order
+	}
+
+	events, err := o.statusChangeEvents(*resultData, history)
+	if err != nil {
+		log.Errorf("[OrderService-6] UpdateStatus: %v", err)
+		return err
+	}
+
+	if err = o.repo.UpdateOrderStatus(ctx, history, events); err != nil {
+		log.Errorf("[OrderService-7] UpdateStatus: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// CancelOrder implements OrderServiceInterface.
+func (o *orderService) CancelOrder(ctx context.Context, orderID int64, accessToken string) error {
+	var userData entity.JwtUserData
+	if err := json.Unmarshal([]byte(accessToken), &userData); err != nil {
+		log.Errorf("[OrderService-1] CancelOrder: %v", err)
+		return err
+	}
+
+	order, err := o.repo.GetByID(ctx, orderID)
+	if err != nil {
+		log.Errorf("[OrderService-2] CancelOrder: %v", err)
+		return err
+	}
+
+	if order.BuyerID != userData.UserID {
+		err = errors.New("404")
+		log.Infof("[OrderService-3] CancelOrder: order %d does not belong to user %d", orderID, userData.UserID)
+		return err
+	}
+
+	if order.Status != entity.OrderStatusPending && order.Status != entity.OrderStatusConfirmed {
+		err = errors.New("422")
+		log.Errorf("[OrderService-4] CancelOrder: order %d with status %s cannot be cancelled", orderID, order.Status)
+		return err
+	}
+
+	// Product names only decorate the search document, so a product-service outage does not block the cancellation.
+	order.BuyerName = userData.Name
+	order.BuyerEmail = userData.Email
+	productIDs := []int64{}
+	for _, item := range order.OrderItems {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := o.lookupProducts(ctx, productIDs)
+	if err != nil {
+		log.Errorf("[OrderService-5] CancelOrder: %v", err)
+	}
+	for key, item := range order.OrderItems {
+		product, found := products[item.ProductID]
+		if !found {
+			continue
+		}
+		order.OrderItems[key].ProductName = product.ProductName
+		order.OrderItems[key].ProductImage = product.ProductImage
+	}
+
+	history := entity.OrderStatusHistoryEntity{
+		OrderID:    orderID,
+		FromStatus: order.Status,
+		ToStatus:   entity.OrderStatusCancelled,
+		Note:       "Cancelled by customer",
+		ChangedBy:  userData.UserID,
+	}
+
+	events, err := o.statusChangeEvents(*order, history)
+	if err != nil {
+		log.Errorf("[OrderService-6] CancelOrder: %v", err)
+		return err
+	}
+
+	if err = o.repo.UpdateOrderStatus(ctx, history, events); err != nil {
+		log.Errorf("[OrderService-7] CancelOrder: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetIndexDocuments implements OrderServiceInterface.
+// Item names, images and buyer details are looked up with one batch request per service, so a failed
+// lookup fails the batch instead of indexing orders without them.
+func (o *orderService) GetIndexDocuments(ctx context.Context, afterID int64, limit int, changedSince time.Time) ([]entity.OrderEntity, error) {
+	results, err := o.repo.GetIndexDocuments(ctx, afterID, limit, changedSince)
+	if err != nil {
+		log.Errorf("[OrderService-1] GetIndexDocuments: %v", err)
+		return nil, err
+	}
+
+	buyers, products, err := o.fetchOrderRelations(ctx, results)
+	if err != nil {
+		log.Errorf("[OrderService-2] GetIndexDocuments: %v", err)
+		return nil, err
+	}
+
+	for key, val := range results {
+		if buyer, found := buyers[val.BuyerID]; found {
+			results[key].BuyerName = buyer.Name
+			results[key].BuyerEmail = buyer.Email
+			results[key].BuyerPhone = buyer.Phone
+			results[key].BuyerAddress = buyer.Address
+		}
+
+		for key2, item := range val.OrderItems {
+			product, found := products[item.ProductID]
+			if !found {
+				continue
+			}
+			results[key].OrderItems[key2].ProductName = product.ProductName
+			results[key].OrderItems[key2].ProductImage = product.ProductImage
+		}
+	}
+
+	return results, nil
+}
+
+// CountIndexDocuments implements OrderServiceInterface.
+func (o *orderService) CountIndexDocuments(ctx context.Context, afterID int64, changedSince time.Time) (int64, error) {
+	return o.repo.CountIndexDocuments(ctx, afterID, changedSince)
+}
+
+// statusChangeEvents builds the outbox events written together with a status change:
+// the refreshed order document for the search index and, on cancellation, the restock request.
+func (o *orderService) statusChangeEvents(order entity.OrderEntity, history entity.OrderStatusHistoryEntity) ([]entity.OutboxEntity, error) {
+	indexEvent, err := o.orderIndexEvent(order, history)
+	if err != nil {
+		log.Errorf("[OrderService-1] statusChangeEvents: %v", err)
+		return nil, err
+	}
+
+	events := []entity.OutboxEntity{indexEvent}
+
+	if history.ToStatus != entity.OrderStatusCancelled {
+		return events, nil
+	}
+
+	restock := entity.PublishRestockEntity{
+		EventKey: fmt.Sprintf("restock-order-%d", order.ID),
+		OrderID:  order.ID,
+	}
+	for _, item := range order.OrderItems {
+		restock.Items = append(restock.Items, entity.PublishOrderItemEntity{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	restockPayload, err := json.Marshal(restock)
+	if err != nil {
+		log.Errorf("[OrderService-2] statusChangeEvents: %v", err)
+		return nil, err
+	}
+
+	events = append(events, entity.OutboxEntity{
+		AggregateType: "order",
+		AggregateID:   order.ID,
+		EventType:     entity.OutboxEventOrderRestock,
+		QueueName:     o.cfg.PublisherName.ProductRestock,
+		MessageID:     restock.EventKey,
+		Payload:       string(restockPayload),
+	})
+
+	return events, nil
+}
+
+// orderIndexEvent builds the outbox event that refreshes the order document in the search index
+// after the status change in history.
+func (o *orderService) orderIndexEvent(order entity.OrderEntity, history entity.OrderStatusHistoryEntity) (entity.OutboxEntity, error) {
+	order.Status = history.ToStatus
+	history.CreatedAt = time.Now()
+	order.StatusHistories = append(order.StatusHistories, history)
+
+	orderPayload, err := json.Marshal(order)
+	if err != nil {
+		log.Errorf("[OrderService-1] orderIndexEvent: %v", err)
+		return entity.OutboxEntity{}, err
+	}
+
+	return entity.OutboxEntity{
+		AggregateType: "order",
+		AggregateID:   order.ID,
+		EventType:     entity.OutboxEventOrderIndexed,
+		QueueName:     o.cfg.PublisherName.OrderPublish,
+		Payload:       string(orderPayload),
+	}, nil
+}
+
+// GetByID implements OrderServiceInterface.
+func (o *orderService) GetByID(ctx context.Context, orderID int64, accessToken string) (*entity.OrderEntity, error) {
+	result, err := o.repo.GetByID(ctx, orderID)
+	if err != nil {
+		log.Errorf("[OrderService-1] GetByID: %v", err)
 		return nil, err
 	}
 
-	userResponse, err := o.httpClientUserService(result.BuyerID, token["token"].(string))
+	buyers, products, err := o.fetchOrderRelations(ctx, []entity.OrderEntity{*result})
 	if err != nil {
 		log.Errorf("[OrderService-2] GetByID: %v", err)
 		return nil, err
 	}
 
+	userResponse := buyers[result.BuyerID]
 	result.BuyerName = userResponse.Name
 	result.BuyerEmail = userResponse.Email
 	result.BuyerPhone = userResponse.Phone
 	result.BuyerAddress = userResponse.Address
 
 	for key, val := range result.OrderItems {
-		productResponse, err := o.httpClientProductService(val.ProductID, token["token"].(string))
-		if err != nil {
-			log.Errorf("[OrderService-3] GetByID: %v", err)
-			return nil, err
-		}
-
+		productResponse := products[val.ProductID]
 		result.OrderItems[key].ProductImage = productResponse.ProductImage
 		result.OrderItems[key].ProductName = productResponse.ProductName
-		result.OrderItems[key].Price = int64(productResponse.SalePrice)
+		if val.Price == 0 {
+			result.OrderItems[key].Price = int64(math.Round(productResponse.SalePrice))
+		}
 	}
 
 	return result, nil
@@ -108,7 +529,8 @@ func (o *orderService) GetAll(ctx context.Context, queryString entity.QueryStrin
 	results, count, total, err := o.elasticRepo.SearchOrderElastic(ctx, queryString)
 	if err == nil {
 		return results, count, total, nil
-	} else {
+	}
+	if !errors.Is(err, breaker.ErrOpen) {
 		log.Errorf("[OrderService-1] GetAll: %v", err)
 	}
 
@@ -118,104 +540,353 @@ func (o *orderService) GetAll(ctx context.Context, queryString entity.QueryStrin
 		return nil, 0, 0, err
 	}
 
-	var token map[string]interface{}
-	err = json.Unmarshal([]byte(accessToken), &token)
+	buyers, products, err := o.fetchOrderRelations(ctx, results)
 	if err != nil {
-		log.Errorf("[OrderService-3] GetByID: %v", err)
+		log.Errorf("[OrderService-3] GetAll: %v", err)
 		return nil, 0, 0, err
 	}
 
 	for key, val := range results {
+		results[key].BuyerName = buyers[val.BuyerID].Name
 
-		userResponse, err := o.httpClientUserService(val.BuyerID, token["token"].(string))
-		if err != nil {
-			log.Errorf("[OrderService-4] GetAll: %v", err)
-			return nil, 0, 0, err
+		for key2, res := range val.OrderItems {
+			results[key].OrderItems[key2].ProductImage = products[res.ProductID].ProductImage
 		}
-		results[key].BuyerName = userResponse.Name
+	}
 
-		for key2, res := range val.OrderItems {
+	return results, count, total, nil
+}
 
-			productResponse, err := o.httpClientProductService(res.ProductID, token["token"].(string))
-			if err != nil {
-				log.Errorf("[OrderService-5] GetAll: %v", err)
-				return nil, 0, 0, err
-			}
+// fetchOrderRelations loads the buyers and products referenced by orders from
+// the lookup cache, fetching the rest with one batch call per service, running
+// both at the same time. Buyers or products that no longer exist are missing
+// from the returned maps.
+func (o *orderService) fetchOrderRelations(ctx context.Context, orders []entity.OrderEntity) (map[int64]entity.CustomerResponseEntity, map[int64]entity.ProductResponseEntity, error) {
+	buyerIDs := []int64{}
+	productIDs := []int64{}
+	seenBuyers := map[int64]bool{}
+	seenProducts := map[int64]bool{}
+	for _, val := range orders {
+		if !seenBuyers[val.BuyerID] {
+			seenBuyers[val.BuyerID] = true
+			buyerIDs = append(buyerIDs, val.BuyerID)
+		}
 
-			results[key].OrderItems[key2].ProductImage = productResponse.ProductImage
+		for _, item := range val.OrderItems {
+			if !seenProducts[item.ProductID] {
+				seenProducts[item.ProductID] = true
+				productIDs = append(productIDs, item.ProductID)
+			}
 		}
 	}
 
-	return results, count, total, nil
+	var (
+		wg         sync.WaitGroup
+		buyers     map[int64]entity.CustomerResponseEntity
+		products   map[int64]entity.ProductResponseEntity
+		buyerErr   error
+		productErr error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		buyers, buyerErr = o.lookupCustomers(ctx, buyerIDs)
+	}()
+	go func() {
+		defer wg.Done()
+		products, productErr = o.lookupProducts(ctx, productIDs)
+	}()
+	wg.Wait()
+
+	if buyerErr != nil {
+		log.Errorf("[OrderService-1] fetchOrderRelations: %v", buyerErr)
+		return nil, nil, buyerErr
+	}
+
+	if productErr != nil {
+		log.Errorf("[OrderService-2] fetchOrderRelations: %v", productErr)
+		return nil, nil, productErr
+	}
+
+	return buyers, products, nil
 }
 
-func (o *orderService) httpClientUserService(userID int64, accessToken string) (*entity.CustomerResponseEntity, error) {
-	baseUrlUser := fmt.Sprintf("%s/%s", o.cfg.App.UserServiceUrl, "admin/customers/"+strconv.FormatInt(userID, 10))
-	header := map[string]string{
-		"Authorization": "Bearer " + accessToken,
-		"Accept":        "application/json",
+// lookupCustomers returns cached customers and fetches the missing ones from
+// user-service, caching them for the next request.
+func (o *orderService) lookupCustomers(ctx context.Context, customerIDs []int64) (map[int64]entity.CustomerResponseEntity, error) {
+	customers := o.lookupCache.GetCustomers(ctx, customerIDs)
+
+	missing := []int64{}
+	for _, id := range customerIDs {
+		if _, found := customers[id]; !found {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) == 0 {
+		return customers, nil
 	}
-	dataUser, err := o.httpClient.CallURL("GET", baseUrlUser, header, nil)
+
+	fetched, err := o.httpClientUsersByIDs(ctx, missing)
 	if err != nil {
-		log.Errorf("[OrderService-1] httpClientUserService: %v", err)
+		log.Errorf("[OrderService-1] lookupCustomers: %v", err)
 		return nil, err
 	}
 
-	defer dataUser.Body.Close()
+	o.lookupCache.SetCustomers(ctx, fetched)
+	for id, val := range fetched {
+		customers[id] = val
+	}
+
+	return customers, nil
+}
+
+// lookupProducts returns cached products and fetches the missing ones from
+// product-service, caching them for the next request.
+func (o *orderService) lookupProducts(ctx context.Context, productIDs []int64) (map[int64]entity.ProductResponseEntity, error) {
+	products := o.lookupCache.GetProducts(ctx, productIDs)
+
+	missing := []int64{}
+	for _, id := range productIDs {
+		if _, found := products[id]; !found {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) == 0 {
+		return products, nil
+	}
 
-	body, err := io.ReadAll(dataUser.Body)
+	fetched, err := o.httpClientProductsByIDs(ctx, missing)
 	if err != nil {
-		log.Errorf("[OrderService-2] httpClientUserService: %v", err)
+		log.Errorf("[OrderService-1] lookupProducts: %v", err)
 		return nil, err
 	}
 
-	var userResponse entity.UserHttpClientResponse
-	err = json.Unmarshal(body, &userResponse)
+	o.lookupCache.SetProducts(ctx, fetched)
+	for id, val := range fetched {
+		products[id] = val
+	}
+
+	return products, nil
+}
+
+// calculateOrderPrice fills each item's unit price from product-service and returns the items subtotal.
+// Prices are read with one batch request and never from the lookup cache, so an order is always priced
+// with the current price. Products that are missing or not active cannot be ordered.
+func (o *orderService) calculateOrderPrice(ctx context.Context, orderItems []entity.OrderItemEntity) (int64, error) {
+	productIDs := []int64{}
+	seenProducts := map[int64]bool{}
+	for _, item := range orderItems {
+		if !seenProducts[item.ProductID] {
+			seenProducts[item.ProductID] = true
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
+
+	products, err := o.httpClientProductsByIDs(ctx, productIDs)
 	if err != nil {
-		log.Errorf("[OrderService-3] httpClientUserService: %v", err)
-		return nil, err
+		log.Errorf("[OrderService-1] calculateOrderPrice: %v", err)
+		return 0, err
 	}
 
-	return &userResponse.Data, nil
+	var subTotal int64
+	for key, item := range orderItems {
+		productResponse, found := products[item.ProductID]
+		if !found {
+			err = errors.New("404")
+			log.Errorf("[OrderService-2] calculateOrderPrice: product %d not found", item.ProductID)
+			return 0, err
+		}
+
+		if productResponse.ProductStatus != entity.ProductStatusActive {
+			err = errors.New("422")
+			log.Errorf("[OrderService-3] calculateOrderPrice: product %d is %s", item.ProductID, productResponse.ProductStatus)
+			return 0, err
+		}
 
+		orderItems[key].Price = int64(math.Round(productResponse.SalePrice))
+		orderItems[key].ProductName = productResponse.ProductName
+		orderItems[key].ProductImage = productResponse.ProductImage
+		subTotal += orderItems[key].Price * item.Quantity
+	}
+
+	return subTotal, nil
 }
 
-func (o *orderService) httpClientProductService(productID int64, accessToken string) (*entity.ProductResponseEntity, error) {
-	baseUrlProduct := fmt.Sprintf("%s/%s", o.cfg.App.ProductServiceUrl, "admin/products/"+strconv.FormatInt(productID, 10))
+func (o *orderService) httpClientReserveStock(ctx context.Context, reservationCode string, orderItems []entity.OrderItemEntity) error {
+	baseUrlStock := fmt.Sprintf("%s/%s", o.cfg.App.ProductServiceUrl, "internal/stock/reservations")
 	header := map[string]string{
-		"Authorization": "Bearer " + accessToken,
-		"Accept":        "application/json",
+		"X-Internal-Key": o.cfg.App.InternalApiKey,
+		"Accept":         "application/json",
+		"Content-Type":   "application/json",
+	}
+
+	reqReservation := entity.StockReservationRequestEntity{ReservationCode: reservationCode}
+	for _, item := range orderItems {
+		reqReservation.Items = append(reqReservation.Items, entity.PublishOrderItemEntity{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
 	}
-	dataProduct, err := o.httpClient.CallURL("GET", baseUrlProduct, header, nil)
+
+	rawData, err := json.Marshal(reqReservation)
 	if err != nil {
-		log.Errorf("[OrderService-1] httpClientProductService: %v", err)
-		return nil, err
+		log.Errorf("[OrderService-1] httpClientReserveStock: %v", err)
+		return err
+	}
+
+	dataStock, err := o.httpClient.CallURL(ctx, http.MethodPost, baseUrlStock, header, rawData)
+	if err != nil {
+		var statusErr *httpclient.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+			var shortageResponse entity.StockShortageHttpClientResponse
+			if err = json.Unmarshal(statusErr.Body, &shortageResponse); err != nil {
+				log.Errorf("[OrderService-2] httpClientReserveStock: %v", err)
+				return err
+			}
+
+			return &entity.InsufficientStockError{Items: shortageResponse.Data}
+		}
+
+		log.Errorf("[OrderService-3] httpClientReserveStock: %v", err)
+		return upstreamError(err)
 	}
+	dataStock.Body.Close()
 
-	defer dataProduct.Body.Close()
+	return nil
+}
 
-	bodyProduct, err := io.ReadAll(dataProduct.Body)
+// httpClientUpdateReservation confirms or releases a stock reservation; action is "confirm" or "release".
+// Releasing a reservation that was already confirmed returns "409".
+func (o *orderService) httpClientUpdateReservation(ctx context.Context, reservationCode, action string) error {
+	baseUrlStock := fmt.Sprintf("%s/internal/stock/reservations/%s/%s", o.cfg.App.ProductServiceUrl, reservationCode, action)
+	header := map[string]string{
+		"X-Internal-Key": o.cfg.App.InternalApiKey,
+		"Accept":         "application/json",
+	}
+
+	dataStock, err := o.httpClient.CallURL(ctx, http.MethodPost, baseUrlStock, header, nil)
 	if err != nil {
-		log.Errorf("[OrderService-2] httpClientProductService: %v", err)
-		return nil, err
+		log.Errorf("[OrderService-1] httpClientUpdateReservation: stock reservation %s: %v", action, err)
+		var statusErr *httpclient.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+			return errors.New("409")
+		}
+		return upstreamError(err)
+	}
+	dataStock.Body.Close()
+
+	return nil
+}
+
+// httpClientUsersByIDs fetches customers from the user-service internal
+// endpoint in chunks of batchLookupSize IDs and returns them keyed by ID.
+func (o *orderService) httpClientUsersByIDs(ctx context.Context, userIDs []int64) (map[int64]entity.CustomerResponseEntity, error) {
+	header := map[string]string{
+		"X-Internal-Key": o.cfg.App.InternalApiKey,
+		"Accept":         "application/json",
+	}
+
+	users := map[int64]entity.CustomerResponseEntity{}
+	for start := 0; start < len(userIDs); start += batchLookupSize {
+		end := min(start+batchLookupSize, len(userIDs))
+
+		baseUrlUser := fmt.Sprintf("%s/%s", o.cfg.App.UserServiceUrl, "internal/customers?ids="+joinIDs(userIDs[start:end]))
+		var userResponse entity.UsersHttpClientResponse
+		if err := o.httpClientGetJSON(ctx, baseUrlUser, header, &userResponse); err != nil {
+			log.Errorf("[OrderService-1] httpClientUsersByIDs: %v", err)
+			return nil, err
+		}
+
+		for _, val := range userResponse.Data {
+			users[int64(val.ID)] = val
+		}
+	}
+
+	return users, nil
+}
+
+// httpClientProductsByIDs fetches products from the product-service internal
+// endpoint in chunks of batchLookupSize IDs and returns them keyed by ID.
+func (o *orderService) httpClientProductsByIDs(ctx context.Context, productIDs []int64) (map[int64]entity.ProductResponseEntity, error) {
+	header := map[string]string{
+		"X-Internal-Key": o.cfg.App.InternalApiKey,
+		"Accept":         "application/json",
+	}
+
+	products := map[int64]entity.ProductResponseEntity{}
+	for start := 0; start < len(productIDs); start += batchLookupSize {
+		end := min(start+batchLookupSize, len(productIDs))
+
+		baseUrlProduct := fmt.Sprintf("%s/%s", o.cfg.App.ProductServiceUrl, "internal/products?ids="+joinIDs(productIDs[start:end]))
+		var productResponse entity.ProductsHttpClientResponse
+		if err := o.httpClientGetJSON(ctx, baseUrlProduct, header, &productResponse); err != nil {
+			log.Errorf("[OrderService-1] httpClientProductsByIDs: %v", err)
+			return nil, err
+		}
+
+		for _, val := range productResponse.Data {
+			products[int64(val.ID)] = val
+		}
 	}
 
-	var productResponse entity.ProductHttpClientResponse
-	err = json.Unmarshal(bodyProduct, &productResponse)
+	return products, nil
+}
+
+// httpClientGetJSON sends a GET request with the given headers and decodes a successful response into out.
+func (o *orderService) httpClientGetJSON(ctx context.Context, url string, header map[string]string, out interface{}) error {
+	res, err := o.httpClient.CallURL(ctx, http.MethodGet, url, header, nil)
 	if err != nil {
-		log.Errorf("[OrderService-3] httpClientProductService: %v", err)
-		return nil, err
+		return upstreamError(err)
+	}
+	defer res.Body.Close()
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// upstreamError turns a failed call to another service into the error codes the
+// handlers answer with: "404" when the resource is gone, "401" when the
+// forwarded token is rejected and "503" when the service cannot be reached, is
+// failing or its circuit breaker is open. Other errors are returned unchanged.
+func upstreamError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var statusErr *httpclient.StatusError
+	if !errors.As(err, &statusErr) {
+		return errors.New("503")
+	}
+
+	switch {
+	case statusErr.StatusCode == http.StatusNotFound:
+		return errors.New("404")
+	case statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden:
+		return errors.New("401")
+	case statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError:
+		return errors.New("503")
+	}
+
+	return err
+}
+
+func joinIDs(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
 	}
 
-	return &productResponse.Data, nil
+	return strings.Join(parts, ",")
 }
 
-func NewOrderService(repo repository.OrderRepositoryInterface, cfg *config.Config, httpClient httpclient.HttpClient, publisherRabbitMQ message.PublishRabbitMQInterface, elasticRepo repository.ElasticRepositoryInterface) OrderServiceInterface {
+func NewOrderService(repo repository.OrderRepositoryInterface, cfg *config.Config, httpClient httpclient.HttpClient, elasticRepo repository.ElasticRepositoryInterface, lookupCache repository.LookupCacheRepositoryInterface) OrderServiceInterface {
 	return &orderService{
-		repo:              repo,
-		cfg:               cfg,
-		httpClient:        httpClient,
-		publisherRabbitMQ: publisherRabbitMQ,
-		elasticRepo:       elasticRepo,
+		repo:        repo,
+		cfg:         cfg,
+		httpClient:  httpClient,
+		elasticRepo: elasticRepo,
+		lookupCache: lookupCache,
 	}
 }
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"order-service/config"
+	"order-service/internal/adapter/message"
+	"order-service/internal/adapter/repository"
+	"order-service/internal/core/domain/entity"
+	"strconv"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	defaultOutboxMaxAttempts = 10
+	outboxClaimLease         = time.Minute
+	outboxMaxBackoff         = 5 * time.Minute
+)
+
+type OutboxServiceInterface interface {
+	RelayPending(ctx context.Context, batchSize int) (int, error)
+}
+
+type outboxService struct {
+	repo              repository.OutboxRepositoryInterface
+	cfg               *config.Config
+	publisherRabbitMQ message.PublishRabbitMQInterface
+}
+
+// RelayPending implements OutboxServiceInterface.
+func (o *outboxService) RelayPending(ctx context.Context, batchSize int) (int, error) {
+	events, err := o.repo.ClaimPending(ctx, batchSize, outboxClaimLease)
+	if err != nil {
+		log.Errorf("[OutboxService-1] RelayPending: %v", err)
+		return 0, err
+	}
+
+	sent := 0
+	for _, event := range events {
+		messageID := event.MessageID
+		if messageID == "" {
+			messageID = event.EventType + "-" + strconv.FormatInt(event.ID, 10)
+		}
+
+		if err := o.publisherRabbitMQ.Publish(event.QueueName, messageID, []byte(event.Payload)); err != nil {
+			log.Errorf("[OutboxService-2] RelayPending: outbox %d: %v", event.ID, err)
+			o.markFailed(ctx, event, err)
+			continue
+		}
+
+		if err := o.repo.MarkSent(ctx, event.ID); err != nil {
+			log.Errorf("[OutboxService-3] RelayPending: %v", err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// markFailed schedules the next attempt with exponential backoff, or gives up once
+// the event has used all of its attempts.
+func (o *outboxService) markFailed(ctx context.Context, event entity.OutboxEntity, cause error) {
+	maxAttempts := o.cfg.App.OutboxMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultOutboxMaxAttempts
+	}
+
+	event.Attempts++
+	event.LastError = cause.Error()
+	event.Status = entity.OutboxStatusPending
+
+	backoff := time.Duration(1<<uint(event.Attempts)) * time.Second
+	if backoff > outboxMaxBackoff || backoff <= 0 {
+		backoff = outboxMaxBackoff
+	}
+	event.NextAttemptAt = time.Now().Add(backoff)
+
+	if event.Attempts >= maxAttempts {
+		event.Status = entity.OutboxStatusFailed
+		log.Errorf("[OutboxService-1] markFailed: outbox %d gave up after %d attempts", event.ID, event.Attempts)
+	}
+
+	if err := o.repo.MarkFailed(ctx, event); err != nil {
+		log.Errorf("[OutboxService-2] markFailed: %v", err)
+	}
+}
+
+func NewOutboxService(repo repository.OutboxRepositoryInterface, cfg *config.Config, publisherRabbitMQ message.PublishRabbitMQInterface) OutboxServiceInterface {
+	return &outboxService{
+		repo:              repo,
+		cfg:               cfg,
+		publisherRabbitMQ: publisherRabbitMQ,
+	}
+}
@@ -0,0 +1,139 @@
+// Package esquery builds Elasticsearch search requests from typed values so user
+// input is always marshalled as JSON data and never spliced into the query text.
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Query is a single query clause, e.g. {"term": {"status.keyword": "ACTIVE"}}.
+type Query map[string]interface{}
+
+// MatchAll matches every document.
+func MatchAll() Query {
+	return Query{"match_all": map[string]interface{}{}}
+}
+
+// Term matches documents whose field equals value exactly.
+func Term(field string, value interface{}) Query {
+	return Query{"term": map[string]interface{}{field: value}}
+}
+
+// Match runs a full-text match of value against field.
+func Match(field string, value interface{}) Query {
+	return Query{"match": map[string]interface{}{field: value}}
+}
+
+// MultiMatch runs a full-text match of text against several fields.
+func MultiMatch(text string, fields ...string) Query {
+	return Query{"multi_match": map[string]interface{}{
+		"query":  text,
+		"fields": fields,
+	}}
+}
+
+// Range matches documents whose field lies within the given bounds. A nil bound
+// is left open.
+func Range(field string, gte, lte interface{}) Query {
+	bounds := map[string]interface{}{}
+	if gte != nil {
+		bounds["gte"] = gte
+	}
+	if lte != nil {
+		bounds["lte"] = lte
+	}
+
+	return Query{"range": map[string]interface{}{field: bounds}}
+}
+
+// BoolQuery combines clauses. Filter clauses do not affect scoring.
+type BoolQuery struct {
+	Must    []Query `json:"must,omitempty"`
+	Filter  []Query `json:"filter,omitempty"`
+	Should  []Query `json:"should,omitempty"`
+	MustNot []Query `json:"must_not,omitempty"`
+}
+
+// Query wraps the bool query as a clause.
+func (b BoolQuery) Query() Query {
+	return Query{"bool": b}
+}
+
+// Sort orders the hits by a single field.
+type Sort map[string]string
+
+// SortBy sorts on field, defaulting to ascending for anything but SortDesc.
+func SortBy(field, order string) Sort {
+	if order != SortDesc {
+		order = SortAsc
+	}
+
+	return Sort{field: order}
+}
+
+// SortFields maps the sort keys accepted from clients to the indexed field to sort on.
+type SortFields map[string]string
+
+// Sort resolves requested against the whitelist, falling back to fallback when the
+// key is not sortable. It returns no sort at all when fallback is not whitelisted
+// either, so a field that is not in the whitelist never reaches Elasticsearch.
+func (s SortFields) Sort(requested, order, fallback string) []Sort {
+	field, ok := s[requested]
+	if !ok {
+		field, ok = s[fallback]
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return []Sort{SortBy(field, order)}
+}
+
+// SearchRequest is the body of a _search call.
+type SearchRequest struct {
+	From  int    `json:"from"`
+	Size  int    `json:"size"`
+	Query Query  `json:"query,omitempty"`
+	Sort  []Sort `json:"sort,omitempty"`
+}
+
+// Reader marshals the request for the Elasticsearch client.
+func (s SearchRequest) Reader() (io.Reader, error) {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(body), nil
+}
+
+// SearchResponse is the part of a _search response the services read.
+type SearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			ID     string          `json:"_id"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// DecodeSearchResponse reads a _search response body.
+func DecodeSearchResponse(body io.Reader) (*SearchResponse, error) {
+	var result SearchResponse
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
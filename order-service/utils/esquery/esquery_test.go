@@ -0,0 +1,199 @@
+package esquery
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func marshal(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	return string(body)
+}
+
+func TestQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{
+			name:  "term",
+			query: Term("status.keyword", "Pending"),
+			want:  `{"term":{"status.keyword":"Pending"}}`,
+		},
+		{
+			name:  "term with number",
+			query: Term("buyer_id", int64(7)),
+			want:  `{"term":{"buyer_id":7}}`,
+		},
+		{
+			name:  "term with quotes",
+			query: Term("status.keyword", `Pending" } , {"match_all": {}}`),
+			want:  `{"term":{"status.keyword":"Pending\" } , {\"match_all\": {}}"}}`,
+		},
+		{
+			name:  "multi match",
+			query: MultiMatch("ORDER-1", "order_code", "status", "buyer_name"),
+			want:  `{"multi_match":{"fields":["order_code","status","buyer_name"],"query":"ORDER-1"}}`,
+		},
+		{
+			name:  "multi match with quotes and backslash",
+			query: MultiMatch(`Budi "Bud" \ Santoso`, "buyer_name"),
+			want:  `{"multi_match":{"fields":["buyer_name"],"query":"Budi \"Bud\" \\ Santoso"}}`,
+		},
+		{
+			name:  "range with both bounds",
+			query: Range("total_amount", 1000, 5000),
+			want:  `{"range":{"total_amount":{"gte":1000,"lte":5000}}}`,
+		},
+		{
+			name:  "range with lower bound",
+			query: Range("id", 100, nil),
+			want:  `{"range":{"id":{"gte":100}}}`,
+		},
+		{
+			name:  "range with upper bound",
+			query: Range("id", nil, 0),
+			want:  `{"range":{"id":{"lte":0}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshal(t, tt.query); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoolQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query BoolQuery
+		want  string
+	}{
+		{
+			name:  "empty",
+			query: BoolQuery{},
+			want:  `{"bool":{}}`,
+		},
+		{
+			name: "all clauses",
+			query: BoolQuery{
+				Must:    []Query{MultiMatch(`"ORDER-1"`, "order_code")},
+				Filter:  []Query{Term("buyer_id", int64(7))},
+				Should:  []Query{Term("status.keyword", "Pending")},
+				MustNot: []Query{Term("status.keyword", "Cancelled")},
+			},
+			want: `{"bool":{"must":[{"multi_match":{"fields":["order_code"],"query":"\"ORDER-1\""}}],` +
+				`"filter":[{"term":{"buyer_id":7}}],` +
+				`"should":[{"term":{"status.keyword":"Pending"}}],` +
+				`"must_not":[{"term":{"status.keyword":"Cancelled"}}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshal(t, tt.query.Query()); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRequest(t *testing.T) {
+	req := SearchRequest{
+		From:  20,
+		Size:  10,
+		Query: BoolQuery{Filter: []Query{Term("status.keyword", `Pen"ding`)}}.Query(),
+		Sort:  []Sort{SortBy("id", SortAsc)},
+	}
+
+	want := `{"from":20,"size":10,` +
+		`"query":{"bool":{"filter":[{"term":{"status.keyword":"Pen\"ding"}}]}},` +
+		`"sort":[{"id":"asc"}]}`
+
+	body, err := req.Reader()
+	if err != nil {
+		t.Fatalf("Reader: %v", err)
+	}
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestSearchRequestOmitsEmptyParts(t *testing.T) {
+	want := `{"from":0,"size":5}`
+	if got := marshal(t, SearchRequest{Size: 5}); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{order: SortDesc, want: `{"id":"desc"}`},
+		{order: SortAsc, want: `{"id":"asc"}`},
+		{order: `desc"}`, want: `{"id":"asc"}`},
+		{order: "", want: `{"id":"asc"}`},
+	}
+
+	for _, tt := range tests {
+		if got := marshal(t, SortBy("id", tt.order)); got != tt.want {
+			t.Errorf("SortBy(%q): got %s, want %s", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestSortFieldsSort(t *testing.T) {
+	fields := SortFields{
+		"id":         "id",
+		"buyer_name": "buyer_name.keyword",
+	}
+
+	tests := []struct {
+		name      string
+		requested string
+		fallback  string
+		want      string
+	}{
+		{name: "whitelisted key", requested: "buyer_name", fallback: "id", want: `[{"buyer_name.keyword":"desc"}]`},
+		{name: "unknown key uses fallback", requested: "password", fallback: "id", want: `[{"id":"desc"}]`},
+		{name: "indexed field name is not a key", requested: "buyer_name.keyword", fallback: "id", want: `[{"id":"desc"}]`},
+		{name: "unknown fallback skips the sort", requested: "password", fallback: "created_at", want: `null`},
+		{name: "empty keys skip the sort", requested: "", fallback: "", want: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshal(t, fields.Sort(tt.requested, SortDesc, tt.fallback)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRequestWithoutResolvedSort(t *testing.T) {
+	req := SearchRequest{Size: 10, Sort: SortFields{"id": "id"}.Sort("unknown", SortAsc, "unknown")}
+
+	want := `{"from":0,"size":10}`
+	if got := marshal(t, req); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"product-service/internal/adapter/message"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dlqQueue string
+	dlqLimit int
+)
+
+var dlqCmd = &cobra.Command{
+	Use:   "dlq",
+	Short: "Melihat dan mengirim ulang pesan di dead-letter queue",
+}
+
+var dlqListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Menampilkan pesan di dead-letter queue tanpa menghapusnya",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		messages, err := message.InspectDeadLetters(dlqQueue, dlqLimit)
+		if err != nil {
+			return err
+		}
+
+		if len(messages) == 0 {
+			fmt.Printf("Tidak ada pesan di %s.dlq\n", dlqQueue)
+			return nil
+		}
+
+		for i, msg := range messages {
+			fmt.Printf("#%d message_id=%s attempts=%d dead_lettered_at=%s\n", i+1, msg.MessageID, msg.Attempt, msg.DeadLetteredAt)
+			fmt.Printf("   error: %s\n", msg.LastError)
+			fmt.Printf("   body:  %s\n", msg.Body)
+		}
+
+		return nil
+	},
+}
+
+var dlqReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Mengirim ulang pesan dari dead-letter queue ke queue asalnya",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		replayed, err := message.ReplayDeadLetters(dlqQueue, dlqLimit)
+		fmt.Printf("%d pesan dikirim ulang ke %s\n", replayed, dlqQueue)
+		return err
+	},
+}
+
+func init() {
+	dlqCmd.PersistentFlags().StringVar(&dlqQueue, "queue", "", "name of the source queue whose dead-letter queue is used")
+	dlqCmd.PersistentFlags().IntVar(&dlqLimit, "limit", 20, "maximum number of messages to process")
+	dlqCmd.MarkPersistentFlagRequired("queue")
+
+	dlqCmd.AddCommand(dlqListCmd, dlqReplayCmd)
+	rootCmd.AddCommand(dlqCmd)
+}
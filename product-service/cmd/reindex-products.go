@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"product-service/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reindexProductsBatchSize int
+	reindexProductsKeepOld   bool
+)
+
+var reindexProductsCmd = &cobra.Command{
+	Use:   "reindex-products",
+	Short: "Membuat ulang index products dari Postgres lalu memindahkan alias tanpa downtime",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("Reindex products sedang berjalan...")
+		return app.RebuildProductIndex(reindexProductsBatchSize, reindexProductsKeepOld)
+	},
+}
+
+func init() {
+	reindexProductsCmd.Flags().IntVar(&reindexProductsBatchSize, "batch-size", 500, "number of products loaded and bulk indexed per batch")
+	reindexProductsCmd.Flags().BoolVar(&reindexProductsKeepOld, "keep-old", true, "keep the previous index after the alias swap, pass --keep-old=false to delete it")
+	rootCmd.AddCommand(reindexProductsCmd)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+	"product-service/internal/app"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var releaseInterval int
+
+var workerReleaseReservationCmd = &cobra.Command{
+	Use:   "worker-release-reservation",
+	Short: "Menjalankan worker untuk melepas reservasi stock yang sudah kedaluwarsa",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Worker untuk release reservation sedang berjalan...")
+		app.RunReservationReleaser(time.Duration(releaseInterval) * time.Second)
+	},
+}
+
+func init() {
+	workerReleaseReservationCmd.Flags().IntVar(&releaseInterval, "interval", 60, "interval in seconds between release runs")
+	rootCmd.AddCommand(workerReleaseReservationCmd)
+}
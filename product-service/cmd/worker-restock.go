@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+	"product-service/internal/adapter/message"
+
+	"github.com/spf13/cobra"
+)
+
+var workerRestockCmd = &cobra.Command{
+	Use:   "worker-restock",
+	Short: "Menjalankan worker untuk consume RabbitMQ dan mengembalikan stock order yang dibatalkan",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Worker untuk restock sedang berjalan...")
+		message.StartRestockConsumer()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerRestockCmd)
+}
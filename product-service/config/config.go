@@ -8,6 +8,10 @@ type App struct {
 
 	JwtSecretKey string `json:"jwt_secret_key"`
 	JwtIssuer    string `json:"jwt_issuer"`
+
+	InternalApiKey string `json:"internal_api_key"`
+
+	StockReservationTTL int `json:"stock_reservation_ttl"`
 }
 
 type PsqlDB struct {
@@ -25,6 +29,9 @@ type RabbitMQ struct {
 	Port     string `json:"port"`
 	User     string `json:"user"`
 	Password string `json:"password"`
+
+	ConsumerMaxAttempts int `json:"consumer_max_attempts"`
+	ConsumerRetryDelay  int `json:"consumer_retry_delay"`
 }
 
 type Supabase struct {
@@ -44,6 +51,7 @@ type ElasticSearch struct {
 
 type PublisherName struct {
 	ProductUpdateStock string `json:"product_update_stock"`
+	ProductRestock     string `json:"product_restock"`
 	ProductPublish     string `json:"product_publish"`
 	ProductDelete      string `json:"product_delete"`
 	ProductToOrder     string `json:"product_to_order"`
@@ -66,6 +74,10 @@ func NewConfig() *Config {
 			AppEnv:  viper.GetString("APP_ENV"),
 
 			JwtSecretKey: viper.GetString("JWT_SECRET_KEY"),
+
+			InternalApiKey: viper.GetString("INTERNAL_API_KEY"),
+
+			StockReservationTTL: viper.GetInt("STOCK_RESERVATION_TTL"),
 		},
 		Psql: PsqlDB{
 			Host:      viper.GetString("DATABASE_HOST"),
@@ -81,6 +93,9 @@ func NewConfig() *Config {
 			Port:     viper.GetString("RABBITMQ_PORT"),
 			User:     viper.GetString("RABBITMQ_USER"),
 			Password: viper.GetString("RABBITMQ_PASSWORD"),
+
+			ConsumerMaxAttempts: viper.GetInt("RABBITMQ_CONSUMER_MAX_ATTEMPTS"),
+			ConsumerRetryDelay:  viper.GetInt("RABBITMQ_CONSUMER_RETRY_DELAY"),
 		},
 		Storage: Supabase{
 			URL:    viper.GetString("SUPABASE_STORAGE_URL"),
@@ -96,9 +111,10 @@ func NewConfig() *Config {
 		},
 		PublisherName: PublisherName{
 			ProductUpdateStock: viper.GetString("PRODUCT_UPDATE_STOCK_NAME"),
+			ProductRestock:     viper.GetString("PRODUCT_RESTOCK_NAME"),
 			ProductPublish:     viper.GetString("PRODUCT_PUBLISH_NAME"),
 			ProductDelete:      viper.GetString("PRODUCT_DELETE"),
-			ProductToOrder:     viper.GetString("PRODUCT_TO_ORDER"),
+			ProductToOrder:     viper.GetString("PRODUCT_TO_ORDER_NAME"),
 		},
 	}
 }
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS stock_events;
\ No newline at end of file
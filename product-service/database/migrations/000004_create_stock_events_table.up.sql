@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS stock_events (
+    id SERIAL PRIMARY KEY,
+    event_key VARCHAR(120) UNIQUE NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id SERIAL PRIMARY KEY,
+    reservation_code VARCHAR(64) NOT NULL,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'RESERVED',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_stock_reservations_reservation_code ON stock_reservations(reservation_code);
+CREATE INDEX idx_stock_reservations_status_expires_at ON stock_reservations(status, expires_at);
\ No newline at end of file
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
 	"product-service/config"
 	"product-service/internal/adapter"
@@ -9,6 +10,7 @@ import (
 	"product-service/internal/core/domain/entity"
 	"product-service/internal/core/service"
 	"product-service/utils/conv"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -16,6 +18,9 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
+// maxProductIDs caps the number of products fetched in one ?ids= request.
+const maxProductIDs = 100
+
 type ProductHandlerInterface interface {
 	GetAllAdmin(c echo.Context) error
 	GetByIDAdmin(c echo.Context) error
@@ -26,6 +31,10 @@ type ProductHandlerInterface interface {
 	GetAllHome(c echo.Context) error
 	GetAllShop(c echo.Context) error
 	GetDetailHome(c echo.Context) error
+	Search(c echo.Context) error
+	Suggest(c echo.Context) error
+
+	GetByIDsInternal(c echo.Context) error
 }
 
 type productHandler struct {
@@ -180,6 +189,178 @@ func (p *productHandler) GetAllShop(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// Search implements ProductHandlerInterface.
+func (p *productHandler) Search(c echo.Context) error {
+	var (
+		resp      = response.DefaultResponseWithPaginations{}
+		ctx       = c.Request().Context()
+		respLists = []response.ProductHomeListResponse{}
+	)
+
+	orderBy := "created_at"
+	if c.QueryParam("orderBy") != "" {
+		orderBy = c.QueryParam("orderBy")
+	}
+	orderType := "desc"
+	if c.QueryParam("orderType") != "" {
+		orderType = c.QueryParam("orderType")
+	}
+	var page int64 = 1
+	if c.QueryParam("page") != "" {
+		page, _ = conv.StringToInt64(c.QueryParam("page"))
+	}
+	if page <= 0 {
+		page = 1
+	}
+	var perPage int64 = 10
+	if c.QueryParam("limit") != "" {
+		perPage, _ = conv.StringToInt64(c.QueryParam("limit"))
+	}
+	if perPage <= 0 {
+		perPage = 10
+	}
+
+	var startPrice int64 = 0
+	var endPrice int64 = 0
+	if c.QueryParam("price") != "" {
+		price := strings.Split(c.QueryParam("price"), " - ")
+		startPrice, _ = conv.StringToInt64(price[0])
+		if len(price) > 1 {
+			endPrice, _ = conv.StringToInt64(price[1])
+		}
+	}
+
+	reqEntity := entity.QueryStringProduct{
+		Search:       c.QueryParam("search"),
+		OrderBy:      orderBy,
+		OrderType:    orderType,
+		Page:         int(page),
+		Limit:        int(perPage),
+		CategorySlug: c.QueryParam("category"),
+		StartPrice:   startPrice,
+		EndPrice:     endPrice,
+		Unit:         c.QueryParam("unit"),
+	}
+
+	if c.QueryParam("in_stock") != "" {
+		inStock, err := strconv.ParseBool(c.QueryParam("in_stock"))
+		if err == nil {
+			reqEntity.InStock = &inStock
+		}
+	}
+
+	results, facets, totalData, totalPage, err := p.service.SearchProducts(ctx, reqEntity)
+	if err != nil {
+		log.Errorf("[ProductHandler-1] Search: %v", err)
+		if err.Error() == "404" {
+			resp.Message = "Data not found"
+			resp.Data = nil
+			return c.JSON(http.StatusNotFound, resp)
+		}
+
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	for _, result := range results {
+		respLists = append(respLists, response.ProductHomeListResponse{
+			ID:           result.ID,
+			ProductName:  result.Name,
+			ProductImage: result.Image,
+			SalePrice:    int64(result.SalePrice),
+			RegulerPrice: int64(result.RegulerPrice),
+			CategoryName: result.CategoryName,
+		})
+	}
+
+	resp.Message = "success"
+	resp.Data = respLists
+	resp.Pagination = &response.Pagination{
+		Page:       page,
+		TotalCount: totalData,
+		TotalPage:  totalPage,
+		PerPage:    perPage,
+	}
+	if facets != nil {
+		resp.Facets = response.ProductFacetsResponse{
+			Categories:  facetBucketResponses(facets.Categories),
+			PriceRanges: facetBucketResponses(facets.PriceRanges),
+			Units:       facetBucketResponses(facets.Units),
+			Stock:       facetBucketResponses(facets.Stock),
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func facetBucketResponses(buckets []entity.FacetBucketEntity) []response.FacetBucketResponse {
+	respBuckets := []response.FacetBucketResponse{}
+	for _, val := range buckets {
+		respBuckets = append(respBuckets, response.FacetBucketResponse{
+			Key:   val.Key,
+			From:  val.From,
+			To:    val.To,
+			Count: val.Count,
+		})
+	}
+
+	return respBuckets
+}
+
+// Suggest implements ProductHandlerInterface.
+func (p *productHandler) Suggest(c echo.Context) error {
+	var (
+		resp = response.DefaultResponse{}
+		ctx  = c.Request().Context()
+	)
+
+	text := strings.TrimSpace(c.QueryParam("q"))
+	if text == "" {
+		resp.Message = "q is required"
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	var limit int64 = 5
+	if c.QueryParam("limit") != "" {
+		limit, _ = conv.StringToInt64(c.QueryParam("limit"))
+	}
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+
+	result, err := p.service.SuggestProducts(ctx, text, int(limit))
+	if err != nil {
+		log.Errorf("[ProductHandler-1] Suggest: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	respSuggestion := response.ProductSuggestionResponse{
+		Products:   []response.ProductSuggestionItemResponse{},
+		Categories: []response.CategorySuggestionResponse{},
+	}
+	for _, val := range result.Products {
+		respSuggestion.Products = append(respSuggestion.Products, response.ProductSuggestionItemResponse{
+			ID:           val.ID,
+			ProductName:  val.Name,
+			ProductImage: val.Image,
+			CategoryName: val.CategoryName,
+		})
+	}
+	for _, val := range result.Categories {
+		respSuggestion.Categories = append(respSuggestion.Categories, response.CategorySuggestionResponse{
+			Name:  val.Key,
+			Count: val.Count,
+		})
+	}
+
+	resp.Message = "success"
+	resp.Data = respSuggestion
+	return c.JSON(http.StatusOK, resp)
+}
+
 // GetAllHome implements ProductHandlerInterface.
 func (p *productHandler) GetAllHome(c echo.Context) error {
 	var (
@@ -523,6 +704,70 @@ func (p *productHandler) GetByIDAdmin(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// GetByIDsInternal implements ProductHandlerInterface.
+// Other services read product prices and status from GET /internal/products?ids=1,2,3.
+func (p *productHandler) GetByIDsInternal(c echo.Context) error {
+	return p.getProductsByIDs(c, c.QueryParam("ids"))
+}
+
+// getProductsByIDs answers GET /admin/products?ids=1,2,3 with the products
+// that exist among the given IDs, without pagination. Prices are rounded to
+// whole units because they are used to price orders.
+func (p *productHandler) getProductsByIDs(c echo.Context, idsParam string) error {
+	var (
+		resp         = response.DefaultResponse{}
+		ctx          = c.Request().Context()
+		respProducts = []response.ProductDetailResponse{}
+	)
+
+	ids, err := conv.StringToInt64List(idsParam)
+	if err != nil {
+		log.Errorf("[ProductHandler-1] getProductsByIDs: %v", err)
+		resp.Message = "ids must be a comma separated list of numbers"
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	if len(ids) > maxProductIDs {
+		log.Infof("[ProductHandler-2] getProductsByIDs: %d ids requested", len(ids))
+		resp.Message = "too many ids, maximum is " + strconv.Itoa(maxProductIDs)
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	results, err := p.service.GetByIDs(ctx, ids)
+	if err != nil {
+		log.Errorf("[ProductHandler-3] getProductsByIDs: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	for _, val := range results {
+		respProducts = append(respProducts, response.ProductDetailResponse{
+			ID:                 val.ID,
+			ProductName:        val.Name,
+			ParentID:           conv.Int64PointerToInt64(val.ParentID),
+			ProductImage:       val.Image,
+			CategorySlug:       val.CategorySlug,
+			CategoryName:       val.CategoryName,
+			ProductStatus:      val.Status,
+			ProductDescription: val.Description,
+			SalePrice:          int64(math.Round(val.SalePrice)),
+			RegulerPrice:       int64(math.Round(val.RegulerPrice)),
+			Unit:               val.Unit,
+			Weight:             val.Weight,
+			Stock:              val.Stock,
+			CreatedAt:          val.CreatedAt,
+			Child:              []response.ProductChildResponse{},
+		})
+	}
+
+	resp.Message = "success"
+	resp.Data = respProducts
+	return c.JSON(http.StatusOK, resp)
+}
+
 // GetAllAdmin implements ProductHandlerInterface.
 func (p *productHandler) GetAllAdmin(c echo.Context) error {
 	var (
@@ -531,6 +776,10 @@ func (p *productHandler) GetAllAdmin(c echo.Context) error {
 		respProducts = []response.ProductListResponse{}
 	)
 
+	if idsParam := c.QueryParam("ids"); idsParam != "" {
+		return p.getProductsByIDs(c, idsParam)
+	}
+
 	search := c.QueryParam("search")
 	orderBy := "created_at"
 	if c.QueryParam("orderBy") != "" {
@@ -632,6 +881,8 @@ func NewProductHandler(e *echo.Echo, cfg *config.Config, service service.Product
 	homeProduct := e.Group("/products")
 	homeProduct.GET("/home", product.GetAllHome)
 	homeProduct.GET("/shop", product.GetAllShop)
+	homeProduct.GET("/search", product.Search)
+	homeProduct.GET("/suggest", product.Suggest)
 	homeProduct.GET("/home/:id", product.GetDetailHome)
 
 	mid := adapter.NewMiddlewareAdapter(cfg)
@@ -642,5 +893,8 @@ func NewProductHandler(e *echo.Echo, cfg *config.Config, service service.Product
 	adminGroup.PUT("/products/:id", product.EditAdmin)
 	adminGroup.DELETE("/products/:id", product.DeleteAdmin)
 
+	internalGroup := e.Group("/internal", mid.CheckInternalKey())
+	internalGroup.GET("/products", product.GetByIDsInternal)
+
 	return product
 }
@@ -0,0 +1,11 @@
+package request
+
+type StockReservationRequest struct {
+	ReservationCode string                        `json:"reservation_code" validate:"required"`
+	Items           []StockReservationItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type StockReservationItemRequest struct {
+	ProductID int64 `json:"product_id" validate:"required"`
+	Quantity  int64 `json:"quantity" validate:"required,gt=0"`
+}
@@ -9,6 +9,7 @@ type DefaultResponseWithPaginations struct {
 	Message    string      `json:"message"`
 	Data       interface{} `json:"data"`
 	Pagination *Pagination `json:"pagination,omitempty"`
+	Facets     interface{} `json:"facets,omitempty"`
 }
 
 type Pagination struct {
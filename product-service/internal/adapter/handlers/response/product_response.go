@@ -70,3 +70,34 @@ type ProductChildHomeResponse struct {
 	SalePrice    int64  `json:"sale_price"`
 	Image        string `json:"image"`
 }
+
+type FacetBucketResponse struct {
+	Key   string   `json:"key"`
+	From  *float64 `json:"from,omitempty"`
+	To    *float64 `json:"to,omitempty"`
+	Count int64    `json:"count"`
+}
+
+type ProductFacetsResponse struct {
+	Categories  []FacetBucketResponse `json:"categories"`
+	PriceRanges []FacetBucketResponse `json:"price_ranges"`
+	Units       []FacetBucketResponse `json:"units"`
+	Stock       []FacetBucketResponse `json:"stock"`
+}
+
+type ProductSuggestionItemResponse struct {
+	ID           int64  `json:"id"`
+	ProductName  string `json:"product_name"`
+	ProductImage string `json:"product_image"`
+	CategoryName string `json:"category_name"`
+}
+
+type CategorySuggestionResponse struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type ProductSuggestionResponse struct {
+	Products   []ProductSuggestionItemResponse `json:"products"`
+	Categories []CategorySuggestionResponse    `json:"categories"`
+}
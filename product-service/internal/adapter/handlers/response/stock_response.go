@@ -0,0 +1,15 @@
+package response
+
+import "time"
+
+type StockReservationResponse struct {
+	ReservationCode string    `json:"reservation_code"`
+	Status          string    `json:"status"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+type StockShortageResponse struct {
+	ProductID int64 `json:"product_id"`
+	Requested int64 `json:"requested"`
+	Available int64 `json:"available"`
+}
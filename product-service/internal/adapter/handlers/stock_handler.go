@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"product-service/config"
+	"product-service/internal/adapter"
+	"product-service/internal/adapter/handlers/request"
+	"product-service/internal/adapter/handlers/response"
+	"product-service/internal/core/domain/entity"
+	"product-service/internal/core/service"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+type StockHandlerInterface interface {
+	Reserve(c echo.Context) error
+	Confirm(c echo.Context) error
+	Release(c echo.Context) error
+}
+
+type stockHandler struct {
+	stockService service.StockServiceInterface
+}
+
+// Reserve implements StockHandlerInterface.
+func (s *stockHandler) Reserve(c echo.Context) error {
+	var (
+		resp = response.DefaultResponse{}
+		ctx  = c.Request().Context()
+		req  = request.StockReservationRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		log.Errorf("[StockHandler-1] Reserve: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	if err := c.Validate(req); err != nil {
+		log.Errorf("[StockHandler-2] Reserve: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	reqEntity := entity.StockReservationEntity{
+		ReservationCode: req.ReservationCode,
+	}
+	for _, item := range req.Items {
+		reqEntity.Items = append(reqEntity.Items, entity.StockItemEntity{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	result, err := s.stockService.Reserve(ctx, reqEntity)
+	if err != nil {
+		log.Errorf("[StockHandler-3] Reserve: %v", err)
+		var stockErr *entity.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			shortages := []response.StockShortageResponse{}
+			for _, val := range stockErr.Items {
+				shortages = append(shortages, response.StockShortageResponse{
+					ProductID: val.ProductID,
+					Requested: val.Requested,
+					Available: val.Available,
+				})
+			}
+
+			resp.Message = "insufficient stock"
+			resp.Data = shortages
+			return c.JSON(http.StatusConflict, resp)
+		}
+
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	resp.Message = "success"
+	resp.Data = response.StockReservationResponse{
+		ReservationCode: result.ReservationCode,
+		Status:          result.Status,
+		ExpiresAt:       result.ExpiresAt,
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// Confirm implements StockHandlerInterface.
+func (s *stockHandler) Confirm(c echo.Context) error {
+	var (
+		resp = response.DefaultResponse{}
+		ctx  = c.Request().Context()
+	)
+
+	code := c.Param("code")
+	if err := s.stockService.Confirm(ctx, code); err != nil {
+		log.Errorf("[StockHandler-1] Confirm: %v", err)
+		if err.Error() == "404" {
+			resp.Message = "Reservation not found or expired"
+			resp.Data = nil
+			return c.JSON(http.StatusNotFound, resp)
+		}
+
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	resp.Message = "success"
+	resp.Data = nil
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Release implements StockHandlerInterface.
+func (s *stockHandler) Release(c echo.Context) error {
+	var (
+		resp = response.DefaultResponse{}
+		ctx  = c.Request().Context()
+	)
+
+	code := c.Param("code")
+	if err := s.stockService.Release(ctx, code); err != nil {
+		log.Errorf("[StockHandler-1] Release: %v", err)
+		if err.Error() == "404" {
+			resp.Message = "Reservation not found or already released"
+			resp.Data = nil
+			return c.JSON(http.StatusNotFound, resp)
+		}
+
+		if err.Error() == "409" {
+			resp.Message = "Reservation already confirmed"
+			resp.Data = nil
+			return c.JSON(http.StatusConflict, resp)
+		}
+
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	resp.Message = "success"
+	resp.Data = nil
+	return c.JSON(http.StatusOK, resp)
+}
+
+func NewStockHandler(e *echo.Echo, cfg *config.Config, stockService service.StockServiceInterface) StockHandlerInterface {
+	stock := &stockHandler{stockService: stockService}
+
+	mid := adapter.NewMiddlewareAdapter(cfg)
+	internalGroup := e.Group("/internal", mid.CheckInternalKey())
+	internalGroup.POST("/stock/reservations", stock.Reserve)
+	internalGroup.POST("/stock/reservations/:code/confirm", stock.Confirm)
+	internalGroup.POST("/stock/reservations/:code/release", stock.Release)
+
+	return stock
+}
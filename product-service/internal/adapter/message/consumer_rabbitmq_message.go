@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"io"
 	"product-service/config"
+	"product-service/internal/adapter/repository"
 	"product-service/internal/core/domain/entity"
 
+	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/labstack/gommon/log"
 )
 
 func StartDeleteOrderConsumer() {
-	conn, err := config.NewConfig().NewRabbitMQ()
+	cfg := config.NewConfig()
+	conn, err := cfg.NewRabbitMQ()
 	if err != nil {
 		log.Errorf("[StartDeleteOrderConsumer-1] Failed to connect to RabbitMQ: %v", err)
+		return
 	}
 
 	defer conn.Close()
@@ -23,71 +27,60 @@ func StartDeleteOrderConsumer() {
 	ch, err := conn.Channel()
 	if err != nil {
 		log.Errorf("[StartDeleteOrderConsumer-2] Failed to open a channel: %v", err)
+		return
 	}
 
 	defer ch.Close()
 
-	q, err := ch.QueueDeclare(
-		config.NewConfig().PublisherName.ProductDelete,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+	esClient, err := cfg.InitElasticsearch()
 	if err != nil {
-		log.Fatalf("[StartDeleteOrderConsumer-3] Failed to declare queue: %v", err)
+		log.Errorf("[StartDeleteOrderConsumer-3] Failed initialize Elasticsearch client: %v", err)
+		return
 	}
 
-	msgs, err := ch.Consume(
-		q.Name,
-		"",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+	log.Info("RabbitMQ Consumer started...")
+
+	err = consumeWithRetry(ch, cfg, cfg.PublisherName.ProductDelete, func(body []byte) error {
+		return deleteProductDocument(esClient, body)
+	})
 	if err != nil {
-		log.Fatalf("[StartDeleteOrderConsumer-4] Failed to register consumer: %v", err)
+		log.Fatalf("[StartDeleteOrderConsumer-4] Consumer stopped: %v", err)
 	}
+}
 
-	log.Info("RabbitMQ Consumer started...")
+func deleteProductDocument(esClient *elasticsearch.Client, body []byte) error {
+	var data map[string]string
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Errorf("[deleteProductDocument-1] Error decoding message: %v", err)
+		return permanent(err)
+	}
 
-	esClient, err := config.NewConfig().InitElasticsearch()
+	productID := data["ProductID"]
+
+	res, err := esClient.Delete(repository.ProductIndexName, productID)
 	if err != nil {
-		log.Errorf("[StartDeleteOrderConsumer-5] Failed initialize Elasticsearch client: %v", err)
-	}
-
-	forever := make(chan bool)
-	go func() {
-		for d := range msgs {
-			var data map[string]string
-			err := json.Unmarshal(d.Body, &data)
-			if err != nil {
-				log.Errorf("[StartDeleteOrderConsumer-6] Error decoding message: %v", err)
-				continue
-			}
-
-			productID := data["ProductID"]
-
-			res, err := esClient.Delete("products", productID)
-			if err != nil {
-				log.Errorf("[StartDeleteOrderConsumer-8] Error indexing to Elasticsearch: %v", err)
-				continue
-			}
-			defer res.Body.Close()
-		}
-	}()
-
-	log.Infof("[StartDeleteOrderConsumer-10] Waiting for messages. To exit press CTRL+C")
-	<-forever
+		log.Errorf("[deleteProductDocument-2] Error deleting from Elasticsearch: %v", err)
+		return err
+	}
+	defer res.Body.Close()
+
+	// A document that is already gone is the outcome we want.
+	if res.IsError() && res.StatusCode != 404 {
+		resBody, _ := io.ReadAll(res.Body)
+		err = fmt.Errorf("elasticsearch returned %s: %s", res.Status(), string(resBody))
+		log.Errorf("[deleteProductDocument-3] %v", err)
+		return err
+	}
+
+	return nil
 }
 
 func StartConsumer() {
-	conn, err := config.NewConfig().NewRabbitMQ()
+	cfg := config.NewConfig()
+	conn, err := cfg.NewRabbitMQ()
 	if err != nil {
 		log.Errorf("[StartConsumer-1] Failed to connect to RabbitMQ: %v", err)
+		return
 	}
 
 	defer conn.Close()
@@ -95,79 +88,67 @@ func StartConsumer() {
 	ch, err := conn.Channel()
 	if err != nil {
 		log.Errorf("[StartConsumer-2] Failed to open a channel: %v", err)
+		return
 	}
 
 	defer ch.Close()
 
-	q, err := ch.QueueDeclare(
-		config.NewConfig().PublisherName.ProductPublish,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+	esClient, err := cfg.InitElasticsearch()
 	if err != nil {
-		log.Fatalf("[StartConsumer-3] Failed to declare queue: %v", err)
+		log.Errorf("[StartConsumer-3] Failed initialize Elasticsearch client: %v", err)
+		return
 	}
 
-	msgs, err := ch.Consume(
-		q.Name,
-		"",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Fatalf("[StartConsumer-4] Failed to register consumer: %v", err)
+	if err = repository.EnsureProductIndex(context.Background(), esClient); err != nil {
+		log.Errorf("[StartConsumer-4] Failed to prepare products index: %v", err)
+		return
 	}
 
 	log.Info("RabbitMQ Consumer started...")
 
-	esClient, err := config.NewConfig().InitElasticsearch()
+	err = consumeWithRetry(ch, cfg, cfg.PublisherName.ProductPublish, func(body []byte) error {
+		return indexProduct(esClient, body)
+	})
 	if err != nil {
-		log.Errorf("[StartConsumer-5] Failed initialize Elasticsearch client: %v", err)
-	}
-
-	forever := make(chan bool)
-	go func() {
-		for d := range msgs {
-			var product entity.ProductEntity
-			err := json.Unmarshal(d.Body, &product)
-			if err != nil {
-				log.Errorf("[StartConsumer-6] Error decoding message: %v", err)
-				continue
-			}
-
-			// Convert product struct ke JSON
-			productJSON, err := json.Marshal(product)
-			if err != nil {
-				log.Errorf("[StartConsumer-7] Error encoding product to JSON: %v", err)
-				continue
-			}
-
-			// Indexing ke Elasticsearch
-			res, err := esClient.Index(
-				"products",                   // Nama index di Elasticsearch
-				bytes.NewReader(productJSON), // Data JSON
-				esClient.Index.WithDocumentID(fmt.Sprintf("%d", product.ID)), // ID dokumen
-				esClient.Index.WithContext(context.Background()),
-				esClient.Index.WithRefresh("true"),
-			)
-			if err != nil {
-				log.Errorf("[StartConsumer-8] Error indexing to Elasticsearch: %v", err)
-				continue
-			}
-			defer res.Body.Close()
-
-			body, _ := io.ReadAll(res.Body)
-
-			log.Infof("[StartConsumer-9] Product %d berhasil diindex ke Elasticsearch %v", product.ID, string(body))
-		}
-	}()
-
-	log.Infof("[StartConsumer-10] Waiting for messages. To exit press CTRL+C")
-	<-forever
+		log.Fatalf("[StartConsumer-5] Consumer stopped: %v", err)
+	}
+}
+
+func indexProduct(esClient *elasticsearch.Client, body []byte) error {
+	var product entity.ProductEntity
+	if err := json.Unmarshal(body, &product); err != nil {
+		log.Errorf("[indexProduct-1] Error decoding message: %v", err)
+		return permanent(err)
+	}
+
+	// Convert product struct ke JSON
+	productJSON, err := json.Marshal(product)
+	if err != nil {
+		log.Errorf("[indexProduct-2] Error encoding product to JSON: %v", err)
+		return permanent(err)
+	}
+
+	// Indexing ke Elasticsearch
+	res, err := esClient.Index(
+		repository.ProductIndexName,                                  // Nama index di Elasticsearch
+		bytes.NewReader(productJSON),                                 // Data JSON
+		esClient.Index.WithDocumentID(fmt.Sprintf("%d", product.ID)), // ID dokumen
+		esClient.Index.WithContext(context.Background()),
+		esClient.Index.WithRefresh("true"),
+	)
+	if err != nil {
+		log.Errorf("[indexProduct-3] Error indexing to Elasticsearch: %v", err)
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, _ := io.ReadAll(res.Body)
+	if res.IsError() {
+		err = fmt.Errorf("elasticsearch returned %s: %s", res.Status(), string(resBody))
+		log.Errorf("[indexProduct-4] %v", err)
+		return err
+	}
+
+	log.Infof("[indexProduct-5] Product %d berhasil diindex ke Elasticsearch %v", product.ID, string(resBody))
+	return nil
 }
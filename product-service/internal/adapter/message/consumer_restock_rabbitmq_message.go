@@ -0,0 +1,103 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"product-service/config"
+	"product-service/internal/adapter/repository"
+	"product-service/internal/core/domain/entity"
+	"product-service/internal/core/domain/model"
+
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func StartRestockConsumer() {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Errorf("[StartRestockConsumer-1] Failed to connect to database: %v", err)
+		return
+	}
+
+	conn, err := cfg.NewRabbitMQ()
+	if err != nil {
+		log.Errorf("[StartRestockConsumer-2] Failed to connect to RabbitMQ: %v", err)
+		return
+	}
+
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[StartRestockConsumer-3] Failed to open a channel: %v", err)
+		return
+	}
+
+	defer ch.Close()
+
+	rabbitPublisher := NewRabbitMQPublisher(cfg)
+	defer rabbitPublisher.Close()
+
+	indexer := NewProductIndexer(repository.NewProductRepository(db.DB, nil), NewPublishRabbitMQ(cfg, rabbitPublisher))
+
+	log.Info("RabbitMQ Consumer restock started...")
+
+	err = consumeWithRetry(ch, cfg, cfg.PublisherName.ProductRestock, func(body []byte) error {
+		var restock entity.PublishRestockEntity
+		if err := json.Unmarshal(body, &restock); err != nil {
+			log.Errorf("[StartRestockConsumer-4] Failed to decode message: %v", err)
+			return permanent(err)
+		}
+
+		if err := restockProducts(db.DB, restock); err != nil {
+			log.Errorf("[StartRestockConsumer-5] Failed to restock order %d: %v", restock.OrderID, err)
+			return err
+		}
+
+		productIDs := []int64{}
+		for _, item := range restock.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		indexer.IndexProducts(context.Background(), productIDs)
+
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("[StartRestockConsumer-6] Consumer stopped: %v", err)
+	}
+}
+
+// restockProducts adds the order quantities back to stock once per event key,
+// so a redelivered message is recorded as a no-op instead of restoring twice.
+func restockProducts(db *gorm.DB, restock entity.PublishRestockEntity) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		event := model.StockEvent{
+			EventKey:  restock.EventKey,
+			EventType: "restock",
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			log.Infof("[restockProducts-1] Event %s already processed, skipping", restock.EventKey)
+			return nil
+		}
+
+		for _, item := range restock.Items {
+			err := tx.Model(&model.Product{}).
+				Where("id = ?", item.ProductID).
+				Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Infof("[restockProducts-2] Stock restored for order %d", restock.OrderID)
+		return nil
+	})
+}
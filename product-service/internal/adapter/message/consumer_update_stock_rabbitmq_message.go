@@ -1,22 +1,27 @@
 package message
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"product-service/config"
+	"product-service/internal/adapter/repository"
 	"product-service/internal/core/domain/entity"
 	"product-service/internal/core/domain/model"
 
 	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
 )
 
 func StartUpdateStockConsumer() {
-	db, err := config.NewConfig().ConnectionPostgres()
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
 	if err != nil {
 		log.Errorf("[StartConsumerUpdateStock-1] Failed to connect to database: %v", err)
 		return
 	}
 
-	conn, err := config.NewConfig().NewRabbitMQ()
+	conn, err := cfg.NewRabbitMQ()
 	if err != nil {
 		log.Errorf("[StartConsumerUpdateStock-2] Failed to connect to RabbitMQ: %v", err)
 		return
@@ -32,59 +37,48 @@ func StartUpdateStockConsumer() {
 
 	defer ch.Close()
 
-	q, err := ch.QueueDeclare(
-		config.NewConfig().PublisherName.ProductUpdateStock,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Fatalf("[StartConsumer-3] Failed to declare queue: %v", err)
-		return
-	}
+	rabbitPublisher := NewRabbitMQPublisher(cfg)
+	defer rabbitPublisher.Close()
 
-	msgs, err := ch.Consume(
-		q.Name,
-		"",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Fatalf("[StartConsumer-4] Failed to register consumer: %v", err)
-		return
-	}
+	indexer := NewProductIndexer(repository.NewProductRepository(db.DB, nil), NewPublishRabbitMQ(cfg, rabbitPublisher))
 
 	log.Info("RabbitMQ Consumer started...")
 
-	for msg := range msgs {
-		var orderItem entity.PublishOrderItemEntity
-		if err := json.Unmarshal(msg.Body, &orderItem); err != nil {
-			log.Errorf("[StartUpdateStockConsumer-5] Failed to decode message: %v", err)
-			continue
+	err = consumeWithRetry(ch, cfg, cfg.PublisherName.ProductUpdateStock, func(body []byte) error {
+		productID, err := decreaseStock(db.DB, body)
+		if err != nil {
+			return err
 		}
 
-		// Simulasi update stok
-		var product model.Product
-		if err := db.DB.First(&product, orderItem.ProductID).Error; err != nil {
-			log.Errorf("[StartUpdateStockConsumer-6] Failed to find product: %v", err)
-			continue
-		}
+		indexer.IndexProducts(context.Background(), []int64{productID})
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("[StartUpdateStockConsumer-4] Consumer stopped: %v", err)
+	}
+}
 
-		if product.Stock < int(orderItem.Quantity) {
-			log.Errorf("[StartUpdateStockConsumer-7] Stock not enough")
-			continue
-		}
+func decreaseStock(db *gorm.DB, body []byte) (int64, error) {
+	var orderItem entity.PublishOrderItemEntity
+	if err := json.Unmarshal(body, &orderItem); err != nil {
+		log.Errorf("[decreaseStock-1] Failed to decode message: %v", err)
+		return 0, permanent(err)
+	}
 
-		product.Stock -= int(orderItem.Quantity)
-		if err := db.DB.Save(&product).Error; err != nil {
-			log.Errorf("[StartUpdateStockConsumer-8] Failed to update stock: %v", err)
-			continue
-		}
-		log.Printf("Mengurangi stok produk %s sebanyak %d", orderItem.ProductID, orderItem.Quantity)
+	result := db.Model(&model.Product{}).
+		Where("id = ? AND stock >= ?", orderItem.ProductID, orderItem.Quantity).
+		Update("stock", gorm.Expr("stock - ?", orderItem.Quantity))
+	if result.Error != nil {
+		log.Errorf("[decreaseStock-2] Failed to update stock: %v", result.Error)
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		err := fmt.Errorf("stock not enough or product %d not found", orderItem.ProductID)
+		log.Errorf("[decreaseStock-3] %v", err)
+		return 0, permanent(err)
 	}
+
+	log.Printf("Mengurangi stok produk %d sebanyak %d", orderItem.ProductID, orderItem.Quantity)
+	return orderItem.ProductID, nil
 }
@@ -0,0 +1,123 @@
+package message
+
+import (
+	"context"
+	"product-service/internal/adapter/repository"
+
+	"github.com/labstack/gommon/log"
+)
+
+// ProductIndexerInterface keeps the products index in line with Postgres by
+// publishing the full product document, category name and variants included.
+// Every change is also announced to order-service so it drops its cached copy.
+type ProductIndexerInterface interface {
+	IndexProduct(ctx context.Context, productID int64) error
+	IndexProducts(ctx context.Context, productIDs []int64)
+	IndexProductsAsync(productIDs []int64)
+	RemoveProduct(productID int64) error
+}
+
+type productIndexer struct {
+	repo      repository.ProductRepositoryInterface
+	publisher PublishRabbitMQInterface
+}
+
+// IndexProduct implements ProductIndexerInterface.
+// Variants are embedded in their parent document, so a change to a variant
+// republishes the parent.
+func (p *productIndexer) IndexProduct(ctx context.Context, productID int64) error {
+	changedIDs, err := p.publishDocument(ctx, productID)
+	if len(changedIDs) > 0 {
+		if errChanged := p.publisher.PublishProductChanged(changedIDs); errChanged != nil {
+			log.Errorf("[ProductIndexer-1] IndexProduct: %v", errChanged)
+		}
+	}
+
+	if err != nil {
+		log.Errorf("[ProductIndexer-2] IndexProduct: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// IndexProducts implements ProductIndexerInterface.
+// The change is announced to order-service in one event for all products.
+func (p *productIndexer) IndexProducts(ctx context.Context, productIDs []int64) {
+	changedIDs := []int64{}
+	seen := map[int64]bool{}
+	for _, productID := range productIDs {
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+
+		ids, err := p.publishDocument(ctx, productID)
+		changedIDs = append(changedIDs, ids...)
+		if err != nil {
+			log.Errorf("[ProductIndexer-1] IndexProducts: product %d: %v", productID, err)
+		}
+	}
+
+	if len(changedIDs) == 0 {
+		return
+	}
+
+	if err := p.publisher.PublishProductChanged(changedIDs); err != nil {
+		log.Errorf("[ProductIndexer-2] IndexProducts: %v", err)
+	}
+}
+
+// IndexProductsAsync implements ProductIndexerInterface.
+// It returns at once, so a slow Elasticsearch or RabbitMQ does not hold up the
+// request that changed the stock.
+func (p *productIndexer) IndexProductsAsync(productIDs []int64) {
+	go p.IndexProducts(context.Background(), productIDs)
+}
+
+// publishDocument publishes the document of productID, or of its parent for a
+// variant, and returns the IDs of the products that changed with it. The IDs are
+// returned even when publishing fails.
+func (p *productIndexer) publishDocument(ctx context.Context, productID int64) ([]int64, error) {
+	product, err := p.repo.GetByID(ctx, productID)
+	if err != nil {
+		log.Errorf("[publishDocument-1] %v", err)
+		return nil, err
+	}
+
+	changedIDs := []int64{productID}
+	if product.ParentID != nil {
+		changedIDs = append(changedIDs, *product.ParentID)
+
+		product, err = p.repo.GetByID(ctx, *product.ParentID)
+		if err != nil {
+			log.Errorf("[publishDocument-2] %v", err)
+			return changedIDs, err
+		}
+	}
+
+	if err = p.publisher.PublishProductToQueue(*product); err != nil {
+		log.Errorf("[publishDocument-3] %v", err)
+		return changedIDs, err
+	}
+
+	return changedIDs, nil
+}
+
+// RemoveProduct implements ProductIndexerInterface.
+func (p *productIndexer) RemoveProduct(productID int64) error {
+	if err := p.publisher.PublishProductChanged([]int64{productID}); err != nil {
+		log.Errorf("[ProductIndexer-1] RemoveProduct: %v", err)
+	}
+
+	if err := p.publisher.DeleteProductFromQueue(productID); err != nil {
+		log.Errorf("[ProductIndexer-2] RemoveProduct: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func NewProductIndexer(repo repository.ProductRepositoryInterface, publisher PublishRabbitMQInterface) ProductIndexerInterface {
+	return &productIndexer{repo: repo, publisher: publisher}
+}
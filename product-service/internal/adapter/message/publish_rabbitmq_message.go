@@ -13,108 +13,69 @@ import (
 type PublishRabbitMQInterface interface {
 	PublishProductToQueue(product entity.ProductEntity) error
 	DeleteProductFromQueue(productID int64) error
+	PublishProductChanged(productIDs []int64) error
 }
 
 type PublishRabbitMQ struct {
-	cfg *config.Config
+	cfg       *config.Config
+	publisher RabbitMQPublisherInterface
 }
 
-func NewPublishRabbitMQ(cfg *config.Config) PublishRabbitMQInterface {
-	return &PublishRabbitMQ{cfg: cfg}
+func NewPublishRabbitMQ(cfg *config.Config, publisher RabbitMQPublisherInterface) PublishRabbitMQInterface {
+	if cfg.PublisherName.ProductToOrder == "" {
+		log.Errorf("[NewPublishRabbitMQ-1] PRODUCT_TO_ORDER_NAME is not set, order-service will not be told about product changes")
+	}
+
+	return &PublishRabbitMQ{cfg: cfg, publisher: publisher}
 }
 
 // DeleteProductFromQueue implements PublishRabbitMQInterface.
 func (p *PublishRabbitMQ) DeleteProductFromQueue(productID int64) error {
-	conn, err := p.cfg.NewRabbitMQ()
-	if err != nil {
-		log.Errorf("[DeleteProductFromQueue-1] Failed to connect to RabbitMQ: %v", err)
-		return err
-	}
-
-	defer conn.Close()
-
-	ch, err := conn.Channel()
-	if err != nil {
-		log.Errorf("[DeleteProductFromQueue-2] Failed to open a channel: %v", err)
-		return err
-	}
-
-	defer ch.Close()
-	q, err := ch.QueueDeclare(
-		p.cfg.PublisherName.ProductDelete,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Errorf("[DeleteProductFromQueue-3] Failed to declare queue: %v", err)
-		return err
-	}
-
 	data, _ := json.Marshal(map[string]string{"ProductID": fmt.Sprintf("%d", productID)})
-	err = ch.Publish(
-		"",
-		q.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        data,
-		},
-	)
+	err := p.publisher.Publish(p.cfg.PublisherName.ProductDelete, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         data,
+	})
 	if err != nil {
-		log.Errorf("[DeleteProductFromQueue-4] Failed to publish message: %v", err)
+		log.Errorf("[DeleteProductFromQueue-1] Failed to publish message: %v", err)
 		return err
 	}
 
 	return nil
 }
 
-func (p *PublishRabbitMQ) PublishProductToQueue(product entity.ProductEntity) error {
-	conn, err := p.cfg.NewRabbitMQ()
-	if err != nil {
-		log.Errorf("[PublishProductToQueue-1] Failed to connect to RabbitMQ: %v", err)
-		return err
+// PublishProductChanged implements PublishRabbitMQInterface.
+// Nothing is sent when no order-service queue is configured, which
+// NewPublishRabbitMQ reports at startup.
+func (p *PublishRabbitMQ) PublishProductChanged(productIDs []int64) error {
+	if p.cfg.PublisherName.ProductToOrder == "" || len(productIDs) == 0 {
+		return nil
 	}
 
-	defer conn.Close()
-
-	ch, err := conn.Channel()
+	data, _ := json.Marshal(entity.ProductChangedEntity{ProductIDs: productIDs})
+	err := p.publisher.Publish(p.cfg.PublisherName.ProductToOrder, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         data,
+	})
 	if err != nil {
-		log.Errorf("[PublishProductToQueue-2] Failed to open a channel: %v", err)
+		log.Errorf("[PublishProductChanged-1] Failed to publish message: %v", err)
 		return err
 	}
 
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(
-		p.cfg.PublisherName.ProductPublish,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Errorf("[PublishProductToQueue-3] Failed to declare queue: %v", err)
-		return err
-	}
+	return nil
+}
 
+func (p *PublishRabbitMQ) PublishProductToQueue(product entity.ProductEntity) error {
 	data, _ := json.Marshal(product)
-	err = ch.Publish(
-		"",
-		q.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        data,
-		},
-	)
+	err := p.publisher.Publish(p.cfg.PublisherName.ProductPublish, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         data,
+	})
 	if err != nil {
-		log.Errorf("[PublishProductToQueue-4] Failed to publish message: %v", err)
+		log.Errorf("[PublishProductToQueue-1] Failed to publish message: %v", err)
 		return err
 	}
 
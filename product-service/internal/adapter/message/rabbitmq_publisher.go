@@ -0,0 +1,268 @@
+package message
+
+import (
+	"errors"
+	"product-service/config"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/streadway/amqp"
+)
+
+const (
+	publisherMaxIdleChannels = 10
+	publisherConfirmTimeout  = 5 * time.Second
+	publisherReconnectDelay  = 2 * time.Second
+	publisherMaxReconnect    = 30 * time.Second
+)
+
+var ErrPublisherClosed = errors.New("rabbitmq publisher is closed")
+
+// RabbitMQPublisherInterface publishes messages over a long-lived connection shared by
+// every publisher of the service.
+type RabbitMQPublisherInterface interface {
+	Publish(queueName string, msg amqp.Publishing) error
+	Close() error
+}
+
+type confirmChannel struct {
+	ch         *amqp.Channel
+	confirms   chan amqp.Confirmation
+	generation int
+}
+
+type rabbitMQPublisher struct {
+	cfg *config.Config
+
+	mu         sync.Mutex
+	conn       *amqp.Connection
+	generation int
+	declared   map[string]bool
+	closed     bool
+
+	idle chan *confirmChannel
+}
+
+// Publish implements RabbitMQPublisherInterface.
+// The message is only reported as sent once the broker confirms it.
+func (r *rabbitMQPublisher) Publish(queueName string, msg amqp.Publishing) error {
+	cc, err := r.acquire()
+	if err != nil {
+		log.Errorf("[RabbitMQPublisher-1] Publish: %v", err)
+		return err
+	}
+
+	if err = r.declareQueue(cc, queueName); err != nil {
+		log.Errorf("[RabbitMQPublisher-2] Publish: %v", err)
+		r.discard(cc)
+		return err
+	}
+
+	if err = cc.ch.Publish("", queueName, false, false, msg); err != nil {
+		log.Errorf("[RabbitMQPublisher-3] Publish: %v", err)
+		r.discard(cc)
+		return err
+	}
+
+	select {
+	case confirm, ok := <-cc.confirms:
+		if !ok {
+			err = amqp.ErrClosed
+			log.Errorf("[RabbitMQPublisher-4] Publish: %v", err)
+			r.discard(cc)
+			return err
+		}
+
+		if !confirm.Ack {
+			err = errors.New("message was not acknowledged by the broker")
+			log.Errorf("[RabbitMQPublisher-5] Publish: %v", err)
+			r.release(cc)
+			return err
+		}
+	case <-time.After(publisherConfirmTimeout):
+		// A late confirm would be read by the next publish on this channel, so drop it.
+		err = errors.New("timed out waiting for publisher confirm")
+		log.Errorf("[RabbitMQPublisher-6] Publish: %v", err)
+		r.discard(cc)
+		return err
+	}
+
+	r.release(cc)
+	return nil
+}
+
+// Close implements RabbitMQPublisherInterface.
+func (r *rabbitMQPublisher) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.drainIdle()
+
+	if r.conn == nil {
+		return nil
+	}
+
+	return r.conn.Close()
+}
+
+// acquire returns an idle confirm channel of the current connection or opens a new one.
+func (r *rabbitMQPublisher) acquire() (*confirmChannel, error) {
+	for {
+		select {
+		case cc := <-r.idle:
+			r.mu.Lock()
+			current := cc.generation == r.generation && r.conn != nil
+			r.mu.Unlock()
+			if current {
+				return cc, nil
+			}
+			cc.ch.Close()
+		default:
+			return r.openChannel()
+		}
+	}
+}
+
+func (r *rabbitMQPublisher) openChannel() (*confirmChannel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, ErrPublisherClosed
+	}
+
+	if r.conn == nil {
+		if err := r.connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	return &confirmChannel{
+		ch:         ch,
+		confirms:   ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+		generation: r.generation,
+	}, nil
+}
+
+// connect dials the broker and watches the connection so it is re-established
+// as soon as it drops. The caller must hold r.mu.
+func (r *rabbitMQPublisher) connect() error {
+	conn, err := r.cfg.NewRabbitMQ()
+	if err != nil {
+		return err
+	}
+
+	r.conn = conn
+	r.generation++
+	r.declared = map[string]bool{}
+
+	go r.watch(conn, conn.NotifyClose(make(chan *amqp.Error, 1)))
+	return nil
+}
+
+func (r *rabbitMQPublisher) watch(conn *amqp.Connection, closed chan *amqp.Error) {
+	reason, ok := <-closed
+	if !ok {
+		// Closed on purpose through Close.
+		return
+	}
+
+	log.Errorf("[RabbitMQPublisher-1] watch: connection closed: %v", reason)
+
+	r.mu.Lock()
+	if r.conn == conn {
+		r.conn = nil
+		r.drainIdle()
+	}
+	r.mu.Unlock()
+
+	delay := publisherReconnectDelay
+	for {
+		r.mu.Lock()
+		if r.closed || r.conn != nil {
+			r.mu.Unlock()
+			return
+		}
+
+		err := r.connect()
+		r.mu.Unlock()
+		if err == nil {
+			log.Info("[RabbitMQPublisher-2] watch: reconnected to RabbitMQ")
+			return
+		}
+
+		log.Errorf("[RabbitMQPublisher-3] watch: reconnect failed: %v", err)
+		time.Sleep(delay)
+		if delay *= 2; delay > publisherMaxReconnect {
+			delay = publisherMaxReconnect
+		}
+	}
+}
+
+func (r *rabbitMQPublisher) declareQueue(cc *confirmChannel, queueName string) error {
+	r.mu.Lock()
+	declared := r.declared[queueName] && cc.generation == r.generation
+	r.mu.Unlock()
+	if declared {
+		return nil
+	}
+
+	if _, err := cc.ch.QueueDeclare(queueName, true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if cc.generation == r.generation {
+		r.declared[queueName] = true
+	}
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *rabbitMQPublisher) release(cc *confirmChannel) {
+	select {
+	case r.idle <- cc:
+	default:
+		cc.ch.Close()
+	}
+}
+
+func (r *rabbitMQPublisher) discard(cc *confirmChannel) {
+	cc.ch.Close()
+}
+
+// drainIdle closes pooled channels. The caller must hold r.mu.
+func (r *rabbitMQPublisher) drainIdle() {
+	for {
+		select {
+		case cc := <-r.idle:
+			cc.ch.Close()
+		default:
+			return
+		}
+	}
+}
+
+func NewRabbitMQPublisher(cfg *config.Config) RabbitMQPublisherInterface {
+	return &rabbitMQPublisher{
+		cfg:      cfg,
+		declared: map[string]bool{},
+		idle:     make(chan *confirmChannel, publisherMaxIdleChannels),
+	}
+}
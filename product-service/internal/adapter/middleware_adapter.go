@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"net/http"
 	"product-service/config"
@@ -15,6 +16,7 @@ import (
 
 type MiddlewareAdapterInterface interface {
 	CheckToken() echo.MiddlewareFunc
+	CheckInternalKey() echo.MiddlewareFunc
 }
 
 type middlewareAdapter struct {
@@ -53,8 +55,8 @@ func (m *middlewareAdapter) CheckToken() echo.MiddlewareFunc {
 
 			getSession, err := redisConn.Get(c.Request().Context(), tokenString).Result()
 			if err != nil || len(getSession) == 0 {
-				log.Errorf("[MiddlewareAdapter-3] CheckToken: %s", err.Error())
-				respErr.Message = err.Error()
+				log.Errorf("[MiddlewareAdapter-3] CheckToken: session not found: %v", err)
+				respErr.Message = "session expired or revoked"
 				respErr.Data = nil
 				return c.JSON(http.StatusUnauthorized, respErr)
 			}
@@ -83,6 +85,27 @@ func (m *middlewareAdapter) CheckToken() echo.MiddlewareFunc {
 	}
 }
 
+// CheckInternalKey implements MiddlewareAdapterInterface.
+// Internal routes are only called by other services, which send the shared
+// INTERNAL_API_KEY in the X-Internal-Key header. They are closed while no key
+// is configured.
+func (m *middlewareAdapter) CheckInternalKey() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			respErr := response.DefaultResponse{}
+			key := c.Request().Header.Get("X-Internal-Key")
+			if m.cfg.App.InternalApiKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(m.cfg.App.InternalApiKey)) != 1 {
+				log.Errorf("[MiddlewareAdapter-1] CheckInternalKey: %s", "missing or invalid internal key")
+				respErr.Message = "missing or invalid internal key"
+				respErr.Data = nil
+				return c.JSON(http.StatusUnauthorized, respErr)
+			}
+
+			return next(c)
+		}
+	}
+}
+
 func NewMiddlewareAdapter(cfg *config.Config) MiddlewareAdapterInterface {
 	return &middlewareAdapter{
 		cfg: cfg,
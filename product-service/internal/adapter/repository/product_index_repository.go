@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"product-service/utils/esindex"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/labstack/gommon/log"
+)
+
+const ProductIndexName = "products"
+
+// ProductIndexMapping defines the versioned indices behind the products alias. Text fields keep a
+// keyword sub-field for filters and sorting, and name and category_name get an
+// edge n-gram sub-field for autocomplete.
+const ProductIndexMapping = `{
+	"settings": {
+		"analysis": {
+			"filter": {
+				"autocomplete_filter": {
+					"type": "edge_ngram",
+					"min_gram": 2,
+					"max_gram": 20
+				}
+			},
+			"analyzer": {
+				"autocomplete": {
+					"type": "custom",
+					"tokenizer": "standard",
+					"filter": ["lowercase", "autocomplete_filter"]
+				}
+			}
+		}
+	},
+	"mappings": {
+		"properties": {
+			"id": { "type": "long" },
+			"parent_id": { "type": "long" },
+			"category_slug": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+			"category_name": {
+				"type": "text",
+				"fields": {
+					"keyword": { "type": "keyword" },
+					"autocomplete": { "type": "text", "analyzer": "autocomplete", "search_analyzer": "standard" }
+				}
+			},
+			"name": {
+				"type": "text",
+				"fields": {
+					"keyword": { "type": "keyword" },
+					"autocomplete": { "type": "text", "analyzer": "autocomplete", "search_analyzer": "standard" }
+				}
+			},
+			"image": { "type": "keyword", "index": false },
+			"description": { "type": "text" },
+			"reguler_price": { "type": "double" },
+			"sale_price": { "type": "double" },
+			"unit": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+			"weight": { "type": "integer" },
+			"stock": { "type": "integer" },
+			"variant": { "type": "integer" },
+			"status": { "type": "text", "fields": { "keyword": { "type": "keyword" } } },
+			"child": { "type": "object" },
+			"created_at": { "type": "date" }
+		}
+	}
+}`
+
+// EnsureProductIndex creates a versioned index with ProductIndexMapping behind
+// the products alias when neither exists yet. An existing alias or index is left
+// as is; rebuild it with the reindex-products command to apply a new mapping.
+func EnsureProductIndex(ctx context.Context, esClient *elasticsearch.Client) error {
+	exists, err := esindex.Exists(ctx, esClient, ProductIndexName)
+	if err != nil {
+		log.Errorf("[EnsureProductIndex-1] %v", err)
+		return err
+	}
+
+	if exists {
+		log.Infof("[EnsureProductIndex-2] Index %s already exists", ProductIndexName)
+		return nil
+	}
+
+	index := esindex.VersionedName(ProductIndexName)
+	if err = esindex.CreateIndex(ctx, esClient, index, ProductIndexMapping); err != nil {
+		log.Errorf("[EnsureProductIndex-3] %v", err)
+		return err
+	}
+
+	if _, err = esindex.SwapAlias(ctx, esClient, ProductIndexName, index); err != nil {
+		log.Errorf("[EnsureProductIndex-4] %v", err)
+		return err
+	}
+
+	log.Infof("[EnsureProductIndex-5] Index %s created behind alias %s", index, ProductIndexName)
+	return nil
+}
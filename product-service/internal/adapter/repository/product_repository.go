@@ -8,8 +8,8 @@ import (
 	"math"
 	"product-service/internal/core/domain/entity"
 	"product-service/internal/core/domain/model"
-	"strconv"
-	"strings"
+	"product-service/utils/esquery"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/labstack/gommon/log"
@@ -19,10 +19,15 @@ import (
 type ProductRepositoryInterface interface {
 	GetAll(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, int64, int64, error)
 	GetByID(ctx context.Context, productID int64) (*entity.ProductEntity, error)
-	Create(ctx context.Context, req entity.ProductEntity) error
+	GetByIDs(ctx context.Context, productIDs []int64) ([]entity.ProductEntity, error)
+	Create(ctx context.Context, req entity.ProductEntity) (int64, error)
 	Update(ctx context.Context, req entity.ProductEntity) error
 	Delete(ctx context.Context, productID int64) error
-	SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, int64, int64, error)
+	SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, *entity.ProductFacetsEntity, int64, int64, error)
+	SuggestProducts(ctx context.Context, text string, limit int) (*entity.ProductSuggestionEntity, error)
+	SuggestProductsDB(ctx context.Context, text string, limit int) (*entity.ProductSuggestionEntity, error)
+	GetIndexDocuments(ctx context.Context, afterID int64, limit int, changedSince time.Time) ([]entity.ProductEntity, error)
+	GetDeletedIndexIDs(ctx context.Context, deletedSince time.Time) ([]int64, error)
 }
 
 type productRepository struct {
@@ -30,105 +35,388 @@ type productRepository struct {
 	esClient *elasticsearch.Client
 }
 
+// productSortFields lists the fields clients may sort products by.
+var productSortFields = esquery.SortFields{
+	"id":            "id",
+	"created_at":    "created_at",
+	"name":          "name.keyword",
+	"sale_price":    "sale_price",
+	"reguler_price": "reguler_price",
+}
+
+// productOrderColumns lists the columns the Postgres listing may be ordered by.
+var productOrderColumns = map[string]string{
+	"id":            "id",
+	"created_at":    "created_at",
+	"name":          "name",
+	"sale_price":    "sale_price",
+	"reguler_price": "reguler_price",
+}
+
+// productPriceRanges are the sale_price buckets shown in the shop filter sidebar.
+var productPriceRanges = []esquery.AggRange{
+	{Key: "0-10000", To: 10000},
+	{Key: "10000-25000", From: 10000, To: 25000},
+	{Key: "25000-50000", From: 25000, To: 50000},
+	{Key: "50000-100000", From: 50000, To: 100000},
+	{Key: "100000+", From: 100000},
+}
+
 // SearchProducts implements ProductRepositoryInterface.
-func (p *productRepository) SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, int64, int64, error) {
-	var mainQueries []string
-	var filterQueries []string
+// Category, price, unit and stock filters are applied as a post filter so the
+// facets keep counting every bucket matching the search term.
+func (p *productRepository) SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, *entity.ProductFacetsEntity, int64, int64, error) {
 	from := (query.Page - 1) * query.Limit
 
-	sortField := "id"
-	if query.OrderBy != "" {
-		sortField = query.OrderBy
+	defaultStatus := "ACTIVE"
+	if query.Status != "" {
+		defaultStatus = query.Status
 	}
 
-	// Menentukan urutan sorting (asc atau desc)
-	sortOrder := "asc"
-	if query.OrderType == "desc" {
-		sortOrder = "desc"
+	boolQuery := esquery.BoolQuery{
+		Filter: []esquery.Query{esquery.Term("status.keyword", defaultStatus)},
 	}
 
-	// Menyusun bagian sort query
-	sortQuery := fmt.Sprintf(`{ "%s": "%s" }`, sortField, sortOrder)
-
+	postFilter := esquery.BoolQuery{}
 	if query.CategorySlug != "" {
-		filterQueries = append(filterQueries, fmt.Sprintf(`{ "term": { "category_slug.keyword": "%s" } }`, query.CategorySlug))
+		postFilter.Filter = append(postFilter.Filter, esquery.Term("category_slug.keyword", query.CategorySlug))
 	}
 
-	if query.StartPrice > 0 && query.EndPrice > 0 {
-		filterQueries = append(filterQueries, fmt.Sprintf(`{ "range": { "reguler_price": { "gte": %d, "lte": %d } } }`, query.StartPrice, query.EndPrice))
+	// Sama seperti query Postgres, filter harga memakai sale_price
+	if query.StartPrice > 0 {
+		postFilter.Filter = append(postFilter.Filter, esquery.Range("sale_price", query.StartPrice, nil))
+	}
+
+	if query.EndPrice > 0 {
+		postFilter.Filter = append(postFilter.Filter, esquery.Range("sale_price", nil, query.EndPrice))
+	}
+
+	if query.Unit != "" {
+		postFilter.Filter = append(postFilter.Filter, esquery.Term("unit.keyword", query.Unit))
+	}
+
+	if query.InStock != nil {
+		if *query.InStock {
+			postFilter.Filter = append(postFilter.Filter, esquery.Range("stock", 1, nil))
+		} else {
+			postFilter.Filter = append(postFilter.Filter, esquery.Range("stock", nil, 0))
+		}
 	}
 
 	if query.Search != "" {
-		mainQueries = append(mainQueries, fmt.Sprintf(`{ "multi_match": { "query": "%s", "fields": ["name", "description", "category_name"] } }`, query.Search))
+		boolQuery.Must = append(boolQuery.Must, esquery.MultiMatch(query.Search, "name", "description", "category_name"))
 	}
 
 	// Query Elasticsearch dengan filtering dan pagination
-	mainQuery := fmt.Sprintf(`{
-		"from": %d,
-		"size": %d,
-		"query": {
-			"bool": {
-				"must": [
-					%s
-				],
-				"filter": [ 
-					%s
-				]
-			}
+	searchRequest := esquery.SearchRequest{
+		From:  from,
+		Size:  query.Limit,
+		Query: boolQuery.Query(),
+		Sort:  productSortFields.Sort(query.OrderBy, query.OrderType, "id"),
+		Aggregations: map[string]esquery.Aggregation{
+			"categories":   esquery.TermsAgg("category_slug.keyword", 50),
+			"price_ranges": esquery.RangeAgg("sale_price", productPriceRanges...),
+			"units":        esquery.TermsAgg("unit.keyword", 20),
+			"stock": esquery.FiltersAgg(map[string]esquery.Query{
+				"in_stock":     esquery.Range("stock", 1, nil),
+				"out_of_stock": esquery.Range("stock", nil, 0),
+			}),
 		},
-		"sort": [
-			%s
-		]
-	}`, from, query.Limit, strings.Join(mainQueries, ","), strings.Join(filterQueries, ","), sortQuery)
+	}
+	if len(postFilter.Filter) > 0 {
+		searchRequest.PostFilter = postFilter.Query()
+	}
+
+	body, err := searchRequest.Reader()
+	if err != nil {
+		log.Errorf("[ProductRepository-1] SearchProducts: %v", err)
+		return nil, nil, 0, 0, err
+	}
 
-	// Query Elasticsearch dengan filtering dan pagination
 	// Kirim query ke Elasticsearch
 	res, err := p.esClient.Search(
 		p.esClient.Search.WithContext(ctx),
-		p.esClient.Search.WithIndex("products"),
-		p.esClient.Search.WithBody(strings.NewReader(mainQuery)),
-		p.esClient.Search.WithPretty(),
+		p.esClient.Search.WithIndex(ProductIndexName),
+		p.esClient.Search.WithBody(body),
 	)
-
 	if err != nil {
-		log.Printf("Error searching Elasticsearch: %s", err)
-		return nil, 0, 0, err
+		log.Errorf("[ProductRepository-2] SearchProducts: %v", err)
+		return nil, nil, 0, 0, err
 	}
 	defer res.Body.Close()
 
-	// Decode response
-	var result map[string]interface{}
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		log.Printf("Error decoding response: %s", err)
-		return nil, 0, 0, err
+	if res.IsError() {
+		err = fmt.Errorf("elasticsearch returned %s", res.Status())
+		log.Errorf("[ProductRepository-3] SearchProducts: %v", err)
+		return nil, nil, 0, 0, err
 	}
 
-	// Ambil total data
-	totalData := 0
-	if hitsTotal, found := result["hits"].(map[string]interface{})["total"].(map[string]interface{}); found {
-		totalData = int(hitsTotal["value"].(float64))
+	result, err := esquery.DecodeSearchResponse(res.Body)
+	if err != nil {
+		log.Errorf("[ProductRepository-4] SearchProducts: %v", err)
+		return nil, nil, 0, 0, err
 	}
 
 	// Hitung total halaman
+	totalData := result.Hits.Total.Value
 	totalPage := 0
 	if query.Limit > 0 {
 		totalPage = int(math.Ceil(float64(totalData) / float64(query.Limit)))
 	}
 
-	// Parsing hasil pencarian ke struct domain.Product
 	products := []entity.ProductEntity{}
-	hits, found := result["hits"].(map[string]interface{})["hits"].([]interface{})
-	if found {
-		for _, hit := range hits {
-			source := hit.(map[string]interface{})["_source"]
-			data, _ := json.Marshal(source)
-			var product entity.ProductEntity
-			json.Unmarshal(data, &product)
-			products = append(products, product)
+	for _, hit := range result.Hits.Hits {
+		var product entity.ProductEntity
+		if err := json.Unmarshal(hit.Source, &product); err != nil {
+			log.Errorf("[ProductRepository-5] SearchProducts: %v", err)
+			continue
+		}
+		products = append(products, product)
+	}
+
+	facets, err := productFacets(result)
+	if err != nil {
+		log.Errorf("[ProductRepository-6] SearchProducts: %v", err)
+		return nil, nil, 0, 0, err
+	}
+
+	// An empty page still carries the facets, so filters that match nothing can be undone.
+	return products, facets, totalData, int64(totalPage), nil
+}
+
+func productFacets(result *esquery.SearchResponse) (*entity.ProductFacetsEntity, error) {
+	facets := entity.ProductFacetsEntity{}
+	targets := map[string]*[]entity.FacetBucketEntity{
+		"categories":   &facets.Categories,
+		"price_ranges": &facets.PriceRanges,
+		"units":        &facets.Units,
+		"stock":        &facets.Stock,
+	}
+
+	for name, target := range targets {
+		buckets, err := result.Buckets(name)
+		if err != nil {
+			return nil, err
+		}
+
+		*target = []entity.FacetBucketEntity{}
+		for _, val := range buckets {
+			*target = append(*target, entity.FacetBucketEntity{
+				Key:   val.Key,
+				From:  val.From,
+				To:    val.To,
+				Count: val.DocCount,
+			})
+		}
+	}
+
+	return &facets, nil
+}
+
+// SuggestProducts implements ProductRepositoryInterface.
+// Matching runs against the edge n-gram sub-fields with fuzziness, so both
+// partial and misspelled words find the product.
+func (p *productRepository) SuggestProducts(ctx context.Context, text string, limit int) (*entity.ProductSuggestionEntity, error) {
+	boolQuery := esquery.BoolQuery{
+		Should: []esquery.Query{
+			esquery.FuzzyMatch("name.autocomplete", text),
+			esquery.FuzzyMatch("name", text),
+			esquery.FuzzyMatch("category_name.autocomplete", text),
+		},
+		Filter:             []esquery.Query{esquery.Term("status.keyword", "ACTIVE")},
+		MinimumShouldMatch: 1,
+	}
+
+	searchRequest := esquery.SearchRequest{
+		Size:   limit,
+		Query:  boolQuery.Query(),
+		Source: []string{"id", "name", "image", "category_name"},
+		Aggregations: map[string]esquery.Aggregation{
+			"categories": esquery.TermsAgg("category_name.keyword", 5),
+		},
+	}
+
+	body, err := searchRequest.Reader()
+	if err != nil {
+		log.Errorf("[ProductRepository-1] SuggestProducts: %v", err)
+		return nil, err
+	}
+
+	res, err := p.esClient.Search(
+		p.esClient.Search.WithContext(ctx),
+		p.esClient.Search.WithIndex(ProductIndexName),
+		p.esClient.Search.WithBody(body),
+	)
+	if err != nil {
+		log.Errorf("[ProductRepository-2] SuggestProducts: %v", err)
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		err = fmt.Errorf("elasticsearch returned %s", res.Status())
+		log.Errorf("[ProductRepository-3] SuggestProducts: %v", err)
+		return nil, err
+	}
+
+	result, err := esquery.DecodeSearchResponse(res.Body)
+	if err != nil {
+		log.Errorf("[ProductRepository-4] SuggestProducts: %v", err)
+		return nil, err
+	}
+
+	suggestion := entity.ProductSuggestionEntity{
+		Products:   []entity.ProductEntity{},
+		Categories: []entity.FacetBucketEntity{},
+	}
+	for _, hit := range result.Hits.Hits {
+		var product entity.ProductEntity
+		if err := json.Unmarshal(hit.Source, &product); err != nil {
+			log.Errorf("[ProductRepository-5] SuggestProducts: %v", err)
+			continue
+		}
+		suggestion.Products = append(suggestion.Products, product)
+	}
+
+	buckets, err := result.Buckets("categories")
+	if err != nil {
+		log.Errorf("[ProductRepository-6] SuggestProducts: %v", err)
+		return nil, err
+	}
+	for _, val := range buckets {
+		suggestion.Categories = append(suggestion.Categories, entity.FacetBucketEntity{
+			Key:   val.Key,
+			Count: val.DocCount,
+		})
+	}
+
+	return &suggestion, nil
+}
+
+// SuggestProductsDB implements ProductRepositoryInterface.
+// It only matches name prefixes and is used when Elasticsearch is unavailable.
+func (p *productRepository) SuggestProductsDB(ctx context.Context, text string, limit int) (*entity.ProductSuggestionEntity, error) {
+	modelProducts := []model.Product{}
+	err := p.db.WithContext(ctx).Preload("Category").
+		Where("parent_id IS NULL AND status = ?", "ACTIVE").
+		Where("name ILIKE ?", text+"%").
+		Order("name asc").
+		Limit(limit).
+		Find(&modelProducts).Error
+	if err != nil {
+		log.Errorf("[ProductRepository-1] SuggestProductsDB: %v", err)
+		return nil, err
+	}
+
+	suggestion := entity.ProductSuggestionEntity{
+		Products:   []entity.ProductEntity{},
+		Categories: []entity.FacetBucketEntity{},
+	}
+	categoryCounts := map[string]int64{}
+	for _, val := range modelProducts {
+		suggestion.Products = append(suggestion.Products, entity.ProductEntity{
+			ID:           val.ID,
+			Name:         val.Name,
+			Image:        val.Image,
+			CategoryName: val.Category.Name,
+		})
+
+		if categoryCounts[val.Category.Name] == 0 {
+			suggestion.Categories = append(suggestion.Categories, entity.FacetBucketEntity{Key: val.Category.Name})
+		}
+		categoryCounts[val.Category.Name]++
+	}
+
+	for key, val := range suggestion.Categories {
+		suggestion.Categories[key].Count = categoryCounts[val.Key]
+	}
+
+	return &suggestion, nil
+}
+
+// GetIndexDocuments implements ProductRepositoryInterface.
+// It returns the next batch of top-level products after afterID, in any status,
+// with their category name and variants as they are stored in the index. A
+// non-zero changedSince only returns products that were created or updated
+// since then, themselves or through one of their variants.
+func (p *productRepository) GetIndexDocuments(ctx context.Context, afterID int64, limit int, changedSince time.Time) ([]entity.ProductEntity, error) {
+	modelProducts := []model.Product{}
+	query := p.db.WithContext(ctx).Preload("Category").Preload("Childs").
+		Where("parent_id IS NULL AND id > ?", afterID)
+	if !changedSince.IsZero() {
+		query = query.Where("(created_at >= ? OR updated_at >= ? OR id IN (?))", changedSince, changedSince,
+			p.db.Unscoped().Model(&model.Product{}).Select("parent_id").
+				Where("parent_id IS NOT NULL AND (created_at >= ? OR updated_at >= ? OR deleted_at >= ?)", changedSince, changedSince, changedSince))
+	}
+
+	err := query.Order("id ASC").
+		Limit(limit).
+		Find(&modelProducts).Error
+	if err != nil {
+		log.Errorf("[ProductRepository-1] GetIndexDocuments: %v", err)
+		return nil, err
+	}
+
+	products := []entity.ProductEntity{}
+	for _, val := range modelProducts {
+		childEntities := []entity.ProductEntity{}
+		for _, child := range val.Childs {
+			childEntities = append(childEntities, entity.ProductEntity{
+				ID:           child.ID,
+				CategorySlug: child.CategorySlug,
+				ParentID:     child.ParentID,
+				Name:         child.Name,
+				Image:        child.Image,
+				Description:  child.Description,
+				RegulerPrice: child.RegulerPrice,
+				SalePrice:    child.SalePrice,
+				Unit:         child.Unit,
+				Weight:       child.Weight,
+				Stock:        child.Stock,
+				Variant:      child.Variant,
+				Status:       child.Status,
+				CategoryName: val.Category.Name,
+				CreatedAt:    child.CreatedAt,
+			})
 		}
+
+		products = append(products, entity.ProductEntity{
+			ID:           val.ID,
+			CategorySlug: val.CategorySlug,
+			ParentID:     val.ParentID,
+			Name:         val.Name,
+			Image:        val.Image,
+			Description:  val.Description,
+			RegulerPrice: val.RegulerPrice,
+			SalePrice:    val.SalePrice,
+			Unit:         val.Unit,
+			Weight:       val.Weight,
+			Stock:        val.Stock,
+			Variant:      val.Variant,
+			Status:       val.Status,
+			CategoryName: val.Category.Name,
+			Child:        childEntities,
+			CreatedAt:    val.CreatedAt,
+		})
+	}
+
+	return products, nil
+}
+
+// GetDeletedIndexIDs implements ProductRepositoryInterface.
+// It returns the top-level products deleted since deletedSince, which have to
+// be removed from the index.
+func (p *productRepository) GetDeletedIndexIDs(ctx context.Context, deletedSince time.Time) ([]int64, error) {
+	productIDs := []int64{}
+	err := p.db.WithContext(ctx).Unscoped().Model(&model.Product{}).
+		Where("parent_id IS NULL AND deleted_at >= ?", deletedSince).
+		Pluck("id", &productIDs).Error
+	if err != nil {
+		log.Errorf("[ProductRepository-1] GetDeletedIndexIDs: %v", err)
+		return nil, err
 	}
 
-	return products, int64(totalData), int64(totalPage), nil
+	return productIDs, nil
 }
 
 // Delete implements ProductRepositoryInterface.
@@ -148,19 +436,6 @@ func (p *productRepository) Delete(ctx context.Context, productID int64) error {
 		return err
 	}
 
-	res, err := p.esClient.Delete(
-		"products",
-		strconv.Itoa(int(productID)),
-		p.esClient.Delete.WithRefresh("true"),
-	)
-	if err != nil {
-		log.Errorf("[ProductRepository-3] Delete: %v", err)
-		return err
-	}
-
-	defer res.Body.Close()
-	log.Infof("[ProductRepository-4] Delete Product Elasticsearch: %d", productID)
-
 	return nil
 }
 
@@ -228,7 +503,7 @@ func (p *productRepository) Update(ctx context.Context, req entity.ProductEntity
 }
 
 // Create implements ProductRepositoryInterface.
-func (p *productRepository) Create(ctx context.Context, req entity.ProductEntity) error {
+func (p *productRepository) Create(ctx context.Context, req entity.ProductEntity) (int64, error) {
 	modelProduct := model.Product{
 		CategorySlug: req.CategorySlug,
 		ParentID:     req.ParentID,
@@ -246,7 +521,7 @@ func (p *productRepository) Create(ctx context.Context, req entity.ProductEntity
 
 	if err := p.db.Create(&modelProduct).Error; err != nil {
 		log.Errorf("[ProductRepository-1] Create: %v", err)
-		return err
+		return 0, err
 	}
 
 	if len(req.Child) > 0 {
@@ -270,11 +545,11 @@ func (p *productRepository) Create(ctx context.Context, req entity.ProductEntity
 
 		if err := p.db.Create(&modelProductChild).Error; err != nil {
 			log.Errorf("[ProductRepository-2] Create: %v", err)
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	return modelProduct.ID, nil
 }
 
 // GetByID implements ProductRepositoryInterface.
@@ -313,7 +588,6 @@ func (p *productRepository) GetByID(ctx context.Context, productID int64) (*enti
 			Variant:      val.Variant,
 			Status:       val.Status,
 			CategoryName: val.Category.Name,
-			Child:        childEntities,
 			CreatedAt:    val.CreatedAt,
 		})
 	}
@@ -338,12 +612,54 @@ func (p *productRepository) GetByID(ctx context.Context, productID int64) (*enti
 	}, nil
 }
 
+// GetByIDs implements ProductRepositoryInterface.
+// IDs that do not exist are skipped and variants are returned without their children.
+func (p *productRepository) GetByIDs(ctx context.Context, productIDs []int64) ([]entity.ProductEntity, error) {
+	modelProducts := []model.Product{}
+
+	if err := p.db.WithContext(ctx).Preload("Category").Where("id IN ?", productIDs).Find(&modelProducts).Error; err != nil {
+		log.Errorf("[ProductRepository-1] GetByIDs: %v", err)
+		return nil, err
+	}
+
+	entities := []entity.ProductEntity{}
+	for _, val := range modelProducts {
+		entities = append(entities, entity.ProductEntity{
+			ID:           val.ID,
+			CategorySlug: val.CategorySlug,
+			ParentID:     val.ParentID,
+			Name:         val.Name,
+			Image:        val.Image,
+			Description:  val.Description,
+			RegulerPrice: val.RegulerPrice,
+			SalePrice:    val.SalePrice,
+			Unit:         val.Unit,
+			Weight:       val.Weight,
+			Stock:        val.Stock,
+			Variant:      val.Variant,
+			Status:       val.Status,
+			CategoryName: val.Category.Name,
+			CreatedAt:    val.CreatedAt,
+		})
+	}
+
+	return entities, nil
+}
+
 // GetAll implements ProductRepositoryInterface.
 func (p *productRepository) GetAll(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, int64, int64, error) {
 	modelProducts := []model.Product{}
 	var countData int64
 
-	order := fmt.Sprintf("%s %s", query.OrderBy, query.OrderType)
+	orderColumn, ok := productOrderColumns[query.OrderBy]
+	if !ok {
+		orderColumn = "created_at"
+	}
+	orderType := "asc"
+	if query.OrderType == "desc" {
+		orderType = "desc"
+	}
+	order := fmt.Sprintf("%s %s", orderColumn, orderType)
 	offset := (query.Page - 1) * query.Limit
 	defaultStatus := "ACTIVE"
 	if query.Status != "" {
@@ -364,6 +680,18 @@ func (p *productRepository) GetAll(ctx context.Context, query entity.QueryString
 		sqlMain = sqlMain.Where("sale_price <= ?", query.EndPrice)
 	}
 
+	if query.Unit != "" {
+		sqlMain = sqlMain.Where("unit = ?", query.Unit)
+	}
+
+	if query.InStock != nil {
+		if *query.InStock {
+			sqlMain = sqlMain.Where("stock > 0")
+		} else {
+			sqlMain = sqlMain.Where("stock <= 0")
+		}
+	}
+
 	if err := sqlMain.Model(&modelProducts).Count(&countData).Error; err != nil {
 		log.Errorf("[ProductRepository-1] GetAll: %v", err)
 		return nil, 0, 0, err
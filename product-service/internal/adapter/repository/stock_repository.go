@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"product-service/internal/core/domain/entity"
+	"product-service/internal/core/domain/model"
+	"sort"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockRepositoryInterface interface {
+	Reserve(ctx context.Context, req entity.StockReservationEntity) error
+	Confirm(ctx context.Context, reservationCode string) error
+	Release(ctx context.Context, reservationCode string) ([]int64, error)
+	ReleaseExpired(ctx context.Context) (int64, []int64, error)
+}
+
+type stockRepository struct {
+	db *gorm.DB
+}
+
+// Reserve implements StockRepositoryInterface.
+// The rows are locked in product_id order so concurrent reservations of the
+// same products wait for each other instead of deadlocking.
+func (s *stockRepository) Reserve(ctx context.Context, req entity.StockReservationEntity) error {
+	items := append([]entity.StockItemEntity{}, req.Items...)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ProductID < items[j].ProductID
+	})
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		shortages := []entity.StockShortageEntity{}
+		reservations := []model.StockReservation{}
+
+		for _, item := range items {
+			result := tx.Model(&model.Product{}).
+				Where("id = ? AND stock >= ?", item.ProductID, item.Quantity).
+				Update("stock", gorm.Expr("stock - ?", item.Quantity))
+			if result.Error != nil {
+				log.Errorf("[StockRepository-1] Reserve: %v", result.Error)
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				var product model.Product
+				available := int64(0)
+				if err := tx.Select("stock").First(&product, "id = ?", item.ProductID).Error; err == nil {
+					available = int64(product.Stock)
+				}
+
+				shortages = append(shortages, entity.StockShortageEntity{
+					ProductID: item.ProductID,
+					Requested: item.Quantity,
+					Available: available,
+				})
+				continue
+			}
+
+			reservations = append(reservations, model.StockReservation{
+				ReservationCode: req.ReservationCode,
+				ProductID:       item.ProductID,
+				Quantity:        item.Quantity,
+				Status:          entity.StockReservationReserved,
+				ExpiresAt:       req.ExpiresAt,
+			})
+		}
+
+		if len(shortages) > 0 {
+			err := &entity.InsufficientStockError{Items: shortages}
+			log.Infof("[StockRepository-2] Reserve: insufficient stock for reservation %s", req.ReservationCode)
+			return err
+		}
+
+		if err := tx.Create(&reservations).Error; err != nil {
+			log.Errorf("[StockRepository-3] Reserve: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Confirm implements StockRepositoryInterface.
+func (s *stockRepository) Confirm(ctx context.Context, reservationCode string) error {
+	result := s.db.WithContext(ctx).Model(&model.StockReservation{}).
+		Where("reservation_code = ? AND status = ?", reservationCode, entity.StockReservationReserved).
+		Updates(map[string]interface{}{"status": entity.StockReservationConfirmed, "updated_at": time.Now()})
+	if result.Error != nil {
+		log.Errorf("[StockRepository-1] Confirm: %v", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		err := errors.New("404")
+		log.Infof("[StockRepository-2] Confirm: no active reservation %s", reservationCode)
+		return err
+	}
+
+	return nil
+}
+
+// Release implements StockRepositoryInterface.
+// It returns "409" for a reservation that was already confirmed, whose stock
+// is only restored by a restock, and "404" when there is nothing to release.
+func (s *stockRepository) Release(ctx context.Context, reservationCode string) ([]int64, error) {
+	var productIDs []int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reservations := []model.StockReservation{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("reservation_code = ? AND status = ?", reservationCode, entity.StockReservationReserved).
+			Find(&reservations).Error
+		if err != nil {
+			log.Errorf("[StockRepository-1] Release: %v", err)
+			return err
+		}
+
+		if len(reservations) == 0 {
+			var confirmed int64
+			err = tx.Model(&model.StockReservation{}).
+				Where("reservation_code = ? AND status = ?", reservationCode, entity.StockReservationConfirmed).
+				Count(&confirmed).Error
+			if err != nil {
+				log.Errorf("[StockRepository-2] Release: %v", err)
+				return err
+			}
+
+			if confirmed > 0 {
+				err = errors.New("409")
+				log.Infof("[StockRepository-3] Release: reservation %s is already confirmed", reservationCode)
+				return err
+			}
+
+			err = errors.New("404")
+			log.Infof("[StockRepository-4] Release: no active reservation %s", reservationCode)
+			return err
+		}
+
+		productIDs, err = releaseReservations(tx, reservations)
+		return err
+	})
+
+	return productIDs, err
+}
+
+// ReleaseExpired implements StockRepositoryInterface.
+func (s *stockRepository) ReleaseExpired(ctx context.Context) (int64, []int64, error) {
+	var (
+		released   int64
+		productIDs []int64
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reservations := []model.StockReservation{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at < ?", entity.StockReservationReserved, time.Now()).
+			Find(&reservations).Error
+		if err != nil {
+			log.Errorf("[StockRepository-1] ReleaseExpired: %v", err)
+			return err
+		}
+
+		if len(reservations) == 0 {
+			return nil
+		}
+
+		released = int64(len(reservations))
+		productIDs, err = releaseReservations(tx, reservations)
+		return err
+	})
+
+	return released, productIDs, err
+}
+
+// releaseReservations puts reserved quantities back on stock, marks the rows released
+// and returns the products whose stock changed.
+func releaseReservations(tx *gorm.DB, reservations []model.StockReservation) ([]int64, error) {
+	ids := []int64{}
+	productIDs := []int64{}
+	for _, val := range reservations {
+		err := tx.Model(&model.Product{}).
+			Where("id = ?", val.ProductID).
+			Update("stock", gorm.Expr("stock + ?", val.Quantity)).Error
+		if err != nil {
+			log.Errorf("[releaseReservations-1] %v", err)
+			return nil, err
+		}
+		ids = append(ids, val.ID)
+		productIDs = append(productIDs, val.ProductID)
+	}
+
+	err := tx.Model(&model.StockReservation{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"status": entity.StockReservationReleased, "updated_at": time.Now()}).Error
+	if err != nil {
+		log.Errorf("[releaseReservations-2] %v", err)
+		return nil, err
+	}
+
+	return productIDs, nil
+}
+
+func NewStockRepository(db *gorm.DB) StockRepositoryInterface {
+	return &stockRepository{db: db}
+}
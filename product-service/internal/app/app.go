@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"product-service/config"
 	"product-service/internal/adapter/handlers"
+	"product-service/internal/adapter/message"
 	"product-service/internal/adapter/repository"
 	"product-service/internal/adapter/storage"
 	"product-service/internal/core/service"
@@ -37,9 +38,16 @@ func RunServer() {
 
 	categoryRepo := repository.NewCategoryRepository(db.DB)
 	productRepo := repository.NewProductRepository(db.DB, elasticInit)
+	stockRepo := repository.NewStockRepository(db.DB)
+
+	rabbitPublisher := message.NewRabbitMQPublisher(cfg)
+	defer rabbitPublisher.Close()
+
+	productIndexer := message.NewProductIndexer(productRepo, message.NewPublishRabbitMQ(cfg, rabbitPublisher))
 
 	categoryService := service.NewCategoryService(categoryRepo)
-	productService := service.NewProductService(productRepo)
+	productService := service.NewProductService(productRepo, productIndexer)
+	stockService := service.NewStockService(stockRepo, cfg, productIndexer)
 
 	e := echo.New()
 	e.Use(middleware.CORS())
@@ -55,6 +63,7 @@ func RunServer() {
 	handlers.NewCategoryHandler(e, categoryService, cfg)
 	handlers.NewProductHandler(e, cfg, productService)
 	handlers.NewUploadImage(e, cfg, storageHandler)
+	handlers.NewStockHandler(e, cfg, stockService)
 
 	go func() {
 		if cfg.App.AppPort == "" {
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"log"
+	"product-service/config"
+	"product-service/internal/adapter/repository"
+	"product-service/utils/esindex"
+	"strconv"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+)
+
+// rebuildCatchUpMargin is subtracted from the catch-up start times so a small
+// clock difference between this process and Postgres cannot skip a change.
+const rebuildCatchUpMargin = time.Minute
+
+// RebuildProductIndex loads every product from Postgres into a new versioned
+// index and then points the products alias at it, so searches keep using the
+// old index until the new one is complete.
+//
+// The consumers keep writing to the alias, that is to the old index, while the
+// batches load. Products changed since the load started are therefore indexed
+// again before the swap, and once more after it for changes made during that
+// catch-up.
+func RebuildProductIndex(batchSize int, keepOld bool) error {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Printf("[RebuildProductIndex-1] %v", err)
+		return err
+	}
+
+	esClient, err := cfg.InitElasticsearch()
+	if err != nil {
+		log.Printf("[RebuildProductIndex-2] %v", err)
+		return err
+	}
+
+	productRepo := repository.NewProductRepository(db.DB, esClient)
+
+	index := esindex.VersionedName(repository.ProductIndexName)
+	if err = esindex.CreateIndex(ctx, esClient, index, repository.ProductIndexMapping); err != nil {
+		log.Printf("[RebuildProductIndex-3] %v", err)
+		return err
+	}
+	log.Printf("[RebuildProductIndex-4] Created index %s", index)
+
+	loadStarted := time.Now().Add(-rebuildCatchUpMargin)
+	if _, err = indexProductBatches(ctx, productRepo, esClient, index, batchSize, time.Time{}); err != nil {
+		log.Printf("[RebuildProductIndex-5] %v", err)
+		return err
+	}
+
+	catchUpStarted := time.Now().Add(-rebuildCatchUpMargin)
+	if err = catchUpProducts(ctx, productRepo, esClient, index, batchSize, loadStarted); err != nil {
+		log.Printf("[RebuildProductIndex-6] %v", err)
+		return err
+	}
+
+	previous, err := esindex.SwapAlias(ctx, esClient, repository.ProductIndexName, index)
+	if err != nil {
+		log.Printf("[RebuildProductIndex-7] %v", err)
+		return err
+	}
+	log.Printf("[RebuildProductIndex-8] Alias %s now points to %s", repository.ProductIndexName, index)
+
+	if err = catchUpProducts(ctx, productRepo, esClient, index, batchSize, catchUpStarted); err != nil {
+		log.Printf("[RebuildProductIndex-9] %v", err)
+		return err
+	}
+
+	if keepOld {
+		return nil
+	}
+
+	if err = esindex.DeleteIndices(ctx, esClient, previous); err != nil {
+		log.Printf("[RebuildProductIndex-10] %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// catchUpProducts writes the products created, updated or deleted since since
+// into index.
+func catchUpProducts(ctx context.Context, productRepo repository.ProductRepositoryInterface, esClient *elasticsearch.Client, index string, batchSize int, since time.Time) error {
+	total, err := indexProductBatches(ctx, productRepo, esClient, index, batchSize, since)
+	if err != nil {
+		log.Printf("[catchUpProducts-1] %v", err)
+		return err
+	}
+
+	deletedIDs, err := productRepo.GetDeletedIndexIDs(ctx, since)
+	if err != nil {
+		log.Printf("[catchUpProducts-2] %v", err)
+		return err
+	}
+
+	docs := []esindex.Document{}
+	for _, id := range deletedIDs {
+		docs = append(docs, esindex.Document{ID: strconv.FormatInt(id, 10)})
+	}
+
+	if err = esindex.Bulk(ctx, esClient, index, docs); err != nil {
+		log.Printf("[catchUpProducts-3] %v", err)
+		return err
+	}
+
+	log.Printf("[catchUpProducts-4] Caught up with %d changed and %d deleted products since %s", total, len(deletedIDs), since.Format(time.RFC3339))
+	return nil
+}
+
+// indexProductBatches bulk indexes the products changed since changedSince, or
+// every product when it is zero, into index one batch at a time. It returns the
+// number of products written.
+func indexProductBatches(ctx context.Context, productRepo repository.ProductRepositoryInterface, esClient *elasticsearch.Client, index string, batchSize int, changedSince time.Time) (int, error) {
+	var (
+		afterID int64
+		total   int
+	)
+	for {
+		products, err := productRepo.GetIndexDocuments(ctx, afterID, batchSize, changedSince)
+		if err != nil {
+			log.Printf("[indexProductBatches-1] %v", err)
+			return total, err
+		}
+
+		if len(products) == 0 {
+			break
+		}
+
+		docs := []esindex.Document{}
+		for _, val := range products {
+			docs = append(docs, esindex.Document{ID: strconv.FormatInt(val.ID, 10), Body: val})
+		}
+
+		if err = esindex.Bulk(ctx, esClient, index, docs); err != nil {
+			log.Printf("[indexProductBatches-2] %v", err)
+			return total, err
+		}
+
+		total += len(products)
+		afterID = products[len(products)-1].ID
+		log.Printf("[indexProductBatches-3] Indexed %d products (last id %d)", total, afterID)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"product-service/config"
+	"product-service/internal/adapter/message"
+	"product-service/internal/adapter/repository"
+	"product-service/internal/core/service"
+	"syscall"
+	"time"
+)
+
+// RunReservationReleaser periodically returns stock held by reservations that
+// were never confirmed before their expiry.
+func RunReservationReleaser(interval time.Duration) {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatalf("[RunReservationReleaser-1] %v", err)
+		return
+	}
+
+	rabbitPublisher := message.NewRabbitMQPublisher(cfg)
+	defer rabbitPublisher.Close()
+
+	productIndexer := message.NewProductIndexer(repository.NewProductRepository(db.DB, nil), message.NewPublishRabbitMQ(cfg, rabbitPublisher))
+	stockService := service.NewStockService(repository.NewStockRepository(db.DB), cfg, productIndexer)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, syscall.SIGTERM)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			released, err := stockService.ReleaseExpired(context.Background())
+			if err != nil {
+				log.Printf("[RunReservationReleaser-2] %v", err)
+				continue
+			}
+
+			if released > 0 {
+				log.Printf("[RunReservationReleaser-3] Released %d expired reservation lines", released)
+			}
+		case <-quit:
+			log.Print("[RunReservationReleaser-4] Stopping reservation releaser...")
+			return
+		}
+	}
+}
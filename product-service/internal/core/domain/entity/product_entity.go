@@ -31,9 +31,41 @@ type QueryStringProduct struct {
 	StartPrice   int64
 	EndPrice     int64
 	Status       string
+	Unit         string
+	InStock      *bool
 }
 
 type PublishOrderItemEntity struct {
 	ProductID int64 `json:"product_id"`
 	Quantity  int64 `json:"quantity"`
 }
+
+type PublishRestockEntity struct {
+	EventKey string                   `json:"event_key"`
+	OrderID  int64                    `json:"order_id"`
+	Items    []PublishOrderItemEntity `json:"items"`
+}
+
+type FacetBucketEntity struct {
+	Key   string
+	From  *float64
+	To    *float64
+	Count int64
+}
+
+type ProductFacetsEntity struct {
+	Categories  []FacetBucketEntity
+	PriceRanges []FacetBucketEntity
+	Units       []FacetBucketEntity
+	Stock       []FacetBucketEntity
+}
+
+type ProductSuggestionEntity struct {
+	Products   []ProductEntity
+	Categories []FacetBucketEntity
+}
+
+// ProductChangedEntity tells order-service which cached products are stale.
+type ProductChangedEntity struct {
+	ProductIDs []int64 `json:"product_ids"`
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+const (
+	StockReservationReserved  = "RESERVED"
+	StockReservationConfirmed = "CONFIRMED"
+	StockReservationReleased  = "RELEASED"
+)
+
+type StockItemEntity struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+type StockReservationEntity struct {
+	ReservationCode string
+	Items           []StockItemEntity
+	Status          string
+	ExpiresAt       time.Time
+}
+
+type StockShortageEntity struct {
+	ProductID int64 `json:"product_id"`
+	Requested int64 `json:"requested"`
+	Available int64 `json:"available"`
+}
+
+// InsufficientStockError is returned when at least one line of a reservation
+// cannot be covered by the current stock. Nothing is reserved in that case.
+type InsufficientStockError struct {
+	Items []StockShortageEntity
+}
+
+func (e *InsufficientStockError) Error() string {
+	return "409"
+}
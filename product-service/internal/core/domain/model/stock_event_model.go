@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type StockEvent struct {
+	ID        int64     `gorm:"primaryKey"`
+	EventKey  string    `gorm:"column:event_key;unique;not null"`
+	EventType string    `gorm:"column:event_type;not null;size:20"`
+	CreatedAt time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type StockReservation struct {
+	ID              int64      `gorm:"primaryKey"`
+	ReservationCode string     `gorm:"column:reservation_code;not null"`
+	ProductID       int64      `gorm:"column:product_id;not null"`
+	Quantity        int64      `gorm:"column:quantity;not null"`
+	Status          string     `gorm:"column:status;default:'RESERVED';size:20"`
+	ExpiresAt       time.Time  `gorm:"column:expires_at;not null"`
+	CreatedAt       time.Time  `gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+	UpdatedAt       *time.Time `gorm:"column:updated_at"`
+}
@@ -2,36 +2,105 @@ package service
 
 import (
 	"context"
+	"product-service/internal/adapter/message"
 	"product-service/internal/adapter/repository"
 	"product-service/internal/core/domain/entity"
+
+	"github.com/labstack/gommon/log"
 )
 
 type ProductServiceInterface interface {
 	GetAll(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, int64, int64, error)
 	GetByID(ctx context.Context, productID int64) (*entity.ProductEntity, error)
+	GetByIDs(ctx context.Context, productIDs []int64) ([]entity.ProductEntity, error)
 	Create(ctx context.Context, req entity.ProductEntity) error
 	Update(ctx context.Context, req entity.ProductEntity) error
 	Delete(ctx context.Context, productID int64) error
-	SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, int64, int64, error)
+	SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, *entity.ProductFacetsEntity, int64, int64, error)
+	SuggestProducts(ctx context.Context, text string, limit int) (*entity.ProductSuggestionEntity, error)
 }
 
 type productService struct {
-	repo repository.ProductRepositoryInterface
+	repo    repository.ProductRepositoryInterface
+	indexer message.ProductIndexerInterface
 }
 
 // SearchProducts implements ProductServiceInterface.
-func (p *productService) SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, int64, int64, error) {
-	return p.repo.SearchProducts(ctx, query)
+// Elasticsearch is preferred and answers an empty page together with its facets; any
+// failure falls back to Postgres, which returns no facets.
+func (p *productService) SearchProducts(ctx context.Context, query entity.QueryStringProduct) ([]entity.ProductEntity, *entity.ProductFacetsEntity, int64, int64, error) {
+	results, facets, count, total, err := p.repo.SearchProducts(ctx, query)
+	if err == nil {
+		return results, facets, count, total, nil
+	} else {
+		log.Errorf("[ProductService-1] SearchProducts: %v", err)
+	}
+
+	results, count, total, err = p.repo.GetAll(ctx, query)
+	if err != nil {
+		log.Errorf("[ProductService-2] SearchProducts: %v", err)
+		return nil, nil, 0, 0, err
+	}
+
+	return results, nil, count, total, nil
+}
+
+// SuggestProducts implements ProductServiceInterface.
+func (p *productService) SuggestProducts(ctx context.Context, text string, limit int) (*entity.ProductSuggestionEntity, error) {
+	result, err := p.repo.SuggestProducts(ctx, text, limit)
+	if err == nil {
+		return result, nil
+	} else {
+		log.Errorf("[ProductService-1] SuggestProducts: %v", err)
+	}
+
+	result, err = p.repo.SuggestProductsDB(ctx, text, limit)
+	if err != nil {
+		log.Errorf("[ProductService-2] SuggestProducts: %v", err)
+		return nil, err
+	}
+
+	return result, nil
 }
 
 // Create implements ProductServiceInterface.
 func (p *productService) Create(ctx context.Context, req entity.ProductEntity) error {
-	return p.repo.Create(ctx, req)
+	productID, err := p.repo.Create(ctx, req)
+	if err != nil {
+		log.Errorf("[ProductService-1] Create: %v", err)
+		return err
+	}
+
+	if err = p.indexer.IndexProduct(ctx, productID); err != nil {
+		log.Errorf("[ProductService-2] Create: %v", err)
+	}
+
+	return nil
 }
 
 // Delete implements ProductServiceInterface.
 func (p *productService) Delete(ctx context.Context, productID int64) error {
-	return p.repo.Delete(ctx, productID)
+	product, err := p.repo.GetByID(ctx, productID)
+	if err != nil {
+		log.Errorf("[ProductService-1] Delete: %v", err)
+		return err
+	}
+
+	if err = p.repo.Delete(ctx, productID); err != nil {
+		log.Errorf("[ProductService-2] Delete: %v", err)
+		return err
+	}
+
+	if product.ParentID != nil {
+		err = p.indexer.IndexProduct(ctx, *product.ParentID)
+	} else {
+		err = p.indexer.RemoveProduct(productID)
+	}
+	if err != nil {
+		log.Errorf("[ProductService-3] Delete: %v", err)
+	}
+
+	return nil
 }
 
 // GetAll implements ProductServiceInterface.
@@ -44,11 +113,25 @@ func (p *productService) GetByID(ctx context.Context, productID int64) (*entity.
 	return p.repo.GetByID(ctx, productID)
 }
 
+// GetByIDs implements ProductServiceInterface.
+func (p *productService) GetByIDs(ctx context.Context, productIDs []int64) ([]entity.ProductEntity, error) {
+	return p.repo.GetByIDs(ctx, productIDs)
+}
+
 // Update implements ProductServiceInterface.
 func (p *productService) Update(ctx context.Context, req entity.ProductEntity) error {
-	return p.repo.Update(ctx, req)
+	if err := p.repo.Update(ctx, req); err != nil {
+		log.Errorf("[ProductService-1] Update: %v", err)
+		return err
+	}
+
+	if err := p.indexer.IndexProduct(ctx, req.ID); err != nil {
+		log.Errorf("[ProductService-2] Update: %v", err)
+	}
+
+	return nil
 }
 
-func NewProductService(repo repository.ProductRepositoryInterface) ProductServiceInterface {
-	return &productService{repo: repo}
+func NewProductService(repo repository.ProductRepositoryInterface, indexer message.ProductIndexerInterface) ProductServiceInterface {
+	return &productService{repo: repo, indexer: indexer}
 }
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"product-service/config"
+	"product-service/internal/adapter/message"
+	"product-service/internal/adapter/repository"
+	"product-service/internal/core/domain/entity"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+const defaultStockReservationTTL = 15
+
+type StockServiceInterface interface {
+	Reserve(ctx context.Context, req entity.StockReservationEntity) (*entity.StockReservationEntity, error)
+	Confirm(ctx context.Context, reservationCode string) error
+	Release(ctx context.Context, reservationCode string) error
+	ReleaseExpired(ctx context.Context) (int64, error)
+}
+
+type stockService struct {
+	repo    repository.StockRepositoryInterface
+	cfg     *config.Config
+	indexer message.ProductIndexerInterface
+}
+
+// Reserve implements StockServiceInterface.
+func (s *stockService) Reserve(ctx context.Context, req entity.StockReservationEntity) (*entity.StockReservationEntity, error) {
+	ttl := s.cfg.App.StockReservationTTL
+	if ttl <= 0 {
+		ttl = defaultStockReservationTTL
+	}
+
+	req.Status = entity.StockReservationReserved
+	req.ExpiresAt = time.Now().Add(time.Duration(ttl) * time.Minute)
+
+	if err := s.repo.Reserve(ctx, req); err != nil {
+		log.Errorf("[StockService-1] Reserve: %v", err)
+		return nil, err
+	}
+
+	productIDs := []int64{}
+	for _, item := range req.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	s.indexer.IndexProductsAsync(productIDs)
+
+	return &req, nil
+}
+
+// Confirm implements StockServiceInterface.
+func (s *stockService) Confirm(ctx context.Context, reservationCode string) error {
+	return s.repo.Confirm(ctx, reservationCode)
+}
+
+// Release implements StockServiceInterface.
+func (s *stockService) Release(ctx context.Context, reservationCode string) error {
+	productIDs, err := s.repo.Release(ctx, reservationCode)
+	if err != nil {
+		log.Errorf("[StockService-1] Release: %v", err)
+		return err
+	}
+
+	s.indexer.IndexProductsAsync(productIDs)
+	return nil
+}
+
+// ReleaseExpired implements StockServiceInterface.
+func (s *stockService) ReleaseExpired(ctx context.Context) (int64, error) {
+	released, productIDs, err := s.repo.ReleaseExpired(ctx)
+	if err != nil {
+		log.Errorf("[StockService-1] ReleaseExpired: %v", err)
+		return 0, err
+	}
+
+	// The releaser runs in its own worker, so it indexes inline and nothing is lost when it stops.
+	s.indexer.IndexProducts(ctx, productIDs)
+	return released, nil
+}
+
+func NewStockService(repo repository.StockRepositoryInterface, cfg *config.Config, indexer message.ProductIndexerInterface) StockServiceInterface {
+	return &stockService{repo: repo, cfg: cfg, indexer: indexer}
+}
@@ -0,0 +1,250 @@
+// Package esindex manages versioned Elasticsearch indices behind a stable alias,
+// so mappings can change and indices can be rebuilt without downtime.
+package esindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// Document is one document written through Bulk. A document without a Body is
+// deleted from the index instead.
+type Document struct {
+	ID   string
+	Body interface{}
+}
+
+// VersionedName returns a new index name for alias, e.g. products_v20240131150405.
+func VersionedName(alias string) string {
+	return fmt.Sprintf("%s_v%s", alias, time.Now().Format("20060102150405"))
+}
+
+// Exists reports whether name exists as an index or an alias.
+func Exists(ctx context.Context, esClient *elasticsearch.Client, name string) (bool, error) {
+	res, err := esClient.Indices.Exists([]string{name}, esClient.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		return true, nil
+	case 404:
+		return false, nil
+	default:
+		return false, responseError(res)
+	}
+}
+
+// CreateIndex creates name with the given settings and mappings body.
+func CreateIndex(ctx context.Context, esClient *elasticsearch.Client, name, mapping string) error {
+	res, err := esClient.Indices.Create(
+		name,
+		esClient.Indices.Create.WithContext(ctx),
+		esClient.Indices.Create.WithBody(strings.NewReader(mapping)),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// AliasTargets returns the indices alias points to. When alias is a concrete
+// index rather than an alias, isIndex is true and no targets are returned.
+func AliasTargets(ctx context.Context, esClient *elasticsearch.Client, alias string) (targets []string, isIndex bool, err error) {
+	exists, err := Exists(ctx, esClient, alias)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	res, err := esClient.Indices.GetAlias(
+		esClient.Indices.GetAlias.WithContext(ctx),
+		esClient.Indices.GetAlias.WithName(alias),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, true, nil
+	}
+	if res.IsError() {
+		return nil, false, responseError(res)
+	}
+
+	result := map[string]interface{}{}
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, false, err
+	}
+
+	for index := range result {
+		targets = append(targets, index)
+	}
+	sort.Strings(targets)
+
+	return targets, false, nil
+}
+
+// SwapAlias points alias at index in one atomic call and returns the indices it
+// pointed to before. A concrete index that still holds the alias name is deleted
+// in the same call.
+func SwapAlias(ctx context.Context, esClient *elasticsearch.Client, alias, index string) ([]string, error) {
+	targets, isIndex, err := AliasTargets(ctx, esClient, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []map[string]interface{}{}
+	if isIndex {
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]string{"index": alias}})
+	}
+	for _, target := range targets {
+		if target != index {
+			actions = append(actions, map[string]interface{}{"remove": map[string]string{"index": target, "alias": alias}})
+		}
+	}
+	actions = append(actions, map[string]interface{}{"add": map[string]string{"index": index, "alias": alias}})
+
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := esClient.Indices.UpdateAliases(
+		bytes.NewReader(body),
+		esClient.Indices.UpdateAliases.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	previous := []string{}
+	for _, target := range targets {
+		if target != index {
+			previous = append(previous, target)
+		}
+	}
+
+	return previous, nil
+}
+
+// DeleteIndices removes the given indices.
+func DeleteIndices(ctx context.Context, esClient *elasticsearch.Client, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	res, err := esClient.Indices.Delete(names, esClient.Indices.Delete.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// Bulk writes docs into index with a single bulk request and fails when any
+// document is rejected.
+func Bulk(ctx context.Context, esClient *elasticsearch.Client, index string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		action := "index"
+		if doc.Body == nil {
+			action = "delete"
+		}
+
+		meta, err := json.Marshal(map[string]interface{}{action: map[string]string{"_index": index, "_id": doc.ID}})
+		if err != nil {
+			return err
+		}
+
+		buf.Write(meta)
+		buf.WriteByte('\n')
+		if doc.Body == nil {
+			continue
+		}
+
+		body, err := json.Marshal(doc.Body)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(body)
+		buf.WriteByte('\n')
+	}
+
+	res, err := esClient.Bulk(bytes.NewReader(buf.Bytes()), esClient.Bulk.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	if !result.Errors {
+		return nil
+	}
+
+	failed := []string{}
+	for _, item := range result.Items {
+		for _, val := range item {
+			if val.Error != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", val.ID, val.Error.Reason))
+			}
+		}
+	}
+
+	return fmt.Errorf("bulk rejected %d documents: %s", len(failed), strings.Join(failed, "; "))
+}
+
+func responseError(res *esapi.Response) error {
+	body, _ := io.ReadAll(res.Body)
+	return fmt.Errorf("elasticsearch returned %s: %s", res.Status(), string(body))
+}
@@ -0,0 +1,258 @@
+// Package esquery builds Elasticsearch search requests from typed values so user
+// input is always marshalled as JSON data and never spliced into the query text.
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Query is a single query clause, e.g. {"term": {"status.keyword": "ACTIVE"}}.
+type Query map[string]interface{}
+
+// MatchAll matches every document.
+func MatchAll() Query {
+	return Query{"match_all": map[string]interface{}{}}
+}
+
+// Term matches documents whose field equals value exactly.
+func Term(field string, value interface{}) Query {
+	return Query{"term": map[string]interface{}{field: value}}
+}
+
+// Match runs a full-text match of value against field.
+func Match(field string, value interface{}) Query {
+	return Query{"match": map[string]interface{}{field: value}}
+}
+
+// FuzzyMatch runs a typo-tolerant full-text match of text against field.
+func FuzzyMatch(field, text string) Query {
+	return Query{"match": map[string]interface{}{field: map[string]interface{}{
+		"query":     text,
+		"fuzziness": "AUTO",
+		"operator":  "and",
+	}}}
+}
+
+// MultiMatch runs a full-text match of text against several fields.
+func MultiMatch(text string, fields ...string) Query {
+	return Query{"multi_match": map[string]interface{}{
+		"query":  text,
+		"fields": fields,
+	}}
+}
+
+// Range matches documents whose field lies within the given bounds. A nil bound
+// is left open.
+func Range(field string, gte, lte interface{}) Query {
+	bounds := map[string]interface{}{}
+	if gte != nil {
+		bounds["gte"] = gte
+	}
+	if lte != nil {
+		bounds["lte"] = lte
+	}
+
+	return Query{"range": map[string]interface{}{field: bounds}}
+}
+
+// BoolQuery combines clauses. Filter clauses do not affect scoring.
+type BoolQuery struct {
+	Must    []Query `json:"must,omitempty"`
+	Filter  []Query `json:"filter,omitempty"`
+	Should  []Query `json:"should,omitempty"`
+	MustNot []Query `json:"must_not,omitempty"`
+
+	MinimumShouldMatch int `json:"minimum_should_match,omitempty"`
+}
+
+// Query wraps the bool query as a clause.
+func (b BoolQuery) Query() Query {
+	return Query{"bool": b}
+}
+
+// Sort orders the hits by a single field.
+type Sort map[string]string
+
+// SortBy sorts on field, defaulting to ascending for anything but SortDesc.
+func SortBy(field, order string) Sort {
+	if order != SortDesc {
+		order = SortAsc
+	}
+
+	return Sort{field: order}
+}
+
+// SortFields maps the sort keys accepted from clients to the indexed field to sort on.
+type SortFields map[string]string
+
+// Sort resolves requested against the whitelist, falling back to fallback when the
+// key is not sortable. It returns no sort at all when fallback is not whitelisted
+// either, so a field that is not in the whitelist never reaches Elasticsearch.
+func (s SortFields) Sort(requested, order, fallback string) []Sort {
+	field, ok := s[requested]
+	if !ok {
+		field, ok = s[fallback]
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return []Sort{SortBy(field, order)}
+}
+
+// Aggregation is a single aggregation, e.g. {"terms": {"field": "unit.keyword"}}.
+type Aggregation map[string]interface{}
+
+// TermsAgg buckets documents by the distinct values of field.
+func TermsAgg(field string, size int) Aggregation {
+	return Aggregation{"terms": map[string]interface{}{
+		"field": field,
+		"size":  size,
+	}}
+}
+
+// AggRange is one bucket of a range aggregation. A nil bound is left open.
+type AggRange struct {
+	Key  string      `json:"key"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// RangeAgg buckets documents by which of ranges field falls into.
+func RangeAgg(field string, ranges ...AggRange) Aggregation {
+	return Aggregation{"range": map[string]interface{}{
+		"field":  field,
+		"ranges": ranges,
+	}}
+}
+
+// FiltersAgg buckets documents by named queries.
+func FiltersAgg(filters map[string]Query) Aggregation {
+	return Aggregation{"filters": map[string]interface{}{
+		"filters": filters,
+	}}
+}
+
+// SearchRequest is the body of a _search call. PostFilter narrows the hits
+// after aggregations are computed.
+type SearchRequest struct {
+	From         int                    `json:"from"`
+	Size         int                    `json:"size"`
+	Query        Query                  `json:"query,omitempty"`
+	PostFilter   Query                  `json:"post_filter,omitempty"`
+	Source       []string               `json:"_source,omitempty"`
+	Sort         []Sort                 `json:"sort,omitempty"`
+	Aggregations map[string]Aggregation `json:"aggs,omitempty"`
+}
+
+// Reader marshals the request for the Elasticsearch client.
+func (s SearchRequest) Reader() (io.Reader, error) {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(body), nil
+}
+
+// SearchResponse is the part of a _search response the services read.
+type SearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			ID     string          `json:"_id"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations"`
+}
+
+// Bucket is one bucket of a terms, range or filters aggregation.
+type Bucket struct {
+	Key      string
+	From     *float64
+	To       *float64
+	DocCount int64
+}
+
+type rawBucket struct {
+	Key      json.RawMessage `json:"key"`
+	From     *float64        `json:"from"`
+	To       *float64        `json:"to"`
+	DocCount int64           `json:"doc_count"`
+}
+
+// Buckets returns the buckets of the named aggregation, in response order for
+// list buckets and sorted by key for keyed buckets. A missing aggregation has no
+// buckets.
+func (s *SearchResponse) Buckets(name string) ([]Bucket, error) {
+	raw, ok := s.Aggregations[name]
+	if !ok {
+		return nil, nil
+	}
+
+	var agg struct {
+		Buckets json.RawMessage `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &agg); err != nil {
+		return nil, err
+	}
+
+	buckets := []Bucket{}
+	if len(agg.Buckets) > 0 && agg.Buckets[0] == '{' {
+		keyed := map[string]rawBucket{}
+		if err := json.Unmarshal(agg.Buckets, &keyed); err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(keyed))
+		for key := range keyed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			val := keyed[key]
+			buckets = append(buckets, Bucket{Key: key, From: val.From, To: val.To, DocCount: val.DocCount})
+		}
+
+		return buckets, nil
+	}
+
+	list := []rawBucket{}
+	if err := json.Unmarshal(agg.Buckets, &list); err != nil {
+		return nil, err
+	}
+
+	for _, val := range list {
+		key := string(val.Key)
+		var text string
+		if err := json.Unmarshal(val.Key, &text); err == nil {
+			key = text
+		}
+		buckets = append(buckets, Bucket{Key: key, From: val.From, To: val.To, DocCount: val.DocCount})
+	}
+
+	return buckets, nil
+}
+
+// DecodeSearchResponse reads a _search response body.
+func DecodeSearchResponse(body io.Reader) (*SearchResponse, error) {
+	var result SearchResponse
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,214 @@
+package esquery
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func marshal(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	return string(body)
+}
+
+func TestQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{
+			name:  "term",
+			query: Term("status.keyword", "ACTIVE"),
+			want:  `{"term":{"status.keyword":"ACTIVE"}}`,
+		},
+		{
+			name:  "term with number",
+			query: Term("buyer_id", int64(7)),
+			want:  `{"term":{"buyer_id":7}}`,
+		},
+		{
+			name:  "term with quotes",
+			query: Term("category_slug.keyword", `sayur" } , {"match_all": {}}`),
+			want:  `{"term":{"category_slug.keyword":"sayur\" } , {\"match_all\": {}}"}}`,
+		},
+		{
+			name:  "multi match",
+			query: MultiMatch("bayam", "name", "description"),
+			want:  `{"multi_match":{"fields":["name","description"],"query":"bayam"}}`,
+		},
+		{
+			name:  "multi match with quotes and backslash",
+			query: MultiMatch(`bayam "segar" \ merah`, "name"),
+			want:  `{"multi_match":{"fields":["name"],"query":"bayam \"segar\" \\ merah"}}`,
+		},
+		{
+			name:  "range with both bounds",
+			query: Range("sale_price", 1000, 5000),
+			want:  `{"range":{"sale_price":{"gte":1000,"lte":5000}}}`,
+		},
+		{
+			name:  "range with lower bound",
+			query: Range("stock", 1, nil),
+			want:  `{"range":{"stock":{"gte":1}}}`,
+		},
+		{
+			name:  "range with upper bound",
+			query: Range("stock", nil, 0),
+			want:  `{"range":{"stock":{"lte":0}}}`,
+		},
+		{
+			name:  "fuzzy match with quotes",
+			query: FuzzyMatch("name", `"bayam`),
+			want:  `{"match":{"name":{"fuzziness":"AUTO","operator":"and","query":"\"bayam"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshal(t, tt.query); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoolQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query BoolQuery
+		want  string
+	}{
+		{
+			name:  "empty",
+			query: BoolQuery{},
+			want:  `{"bool":{}}`,
+		},
+		{
+			name: "all clauses",
+			query: BoolQuery{
+				Must:               []Query{MultiMatch(`"sayur"`, "name")},
+				Filter:             []Query{Term("status.keyword", "ACTIVE")},
+				Should:             []Query{Term("unit.keyword", "kg")},
+				MustNot:            []Query{Range("stock", nil, 0)},
+				MinimumShouldMatch: 1,
+			},
+			want: `{"bool":{"must":[{"multi_match":{"fields":["name"],"query":"\"sayur\""}}],` +
+				`"filter":[{"term":{"status.keyword":"ACTIVE"}}],` +
+				`"should":[{"term":{"unit.keyword":"kg"}}],` +
+				`"must_not":[{"range":{"stock":{"lte":0}}}],` +
+				`"minimum_should_match":1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshal(t, tt.query.Query()); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRequest(t *testing.T) {
+	req := SearchRequest{
+		From:       20,
+		Size:       10,
+		Query:      BoolQuery{Filter: []Query{Term("status.keyword", "ACTIVE")}}.Query(),
+		PostFilter: BoolQuery{Filter: []Query{Term("unit.keyword", `k"g`)}}.Query(),
+		Source:     []string{"id", "name"},
+		Sort:       []Sort{SortBy("sale_price", SortDesc)},
+		Aggregations: map[string]Aggregation{
+			"units": TermsAgg("unit.keyword", 20),
+		},
+	}
+
+	want := `{"from":20,"size":10,` +
+		`"query":{"bool":{"filter":[{"term":{"status.keyword":"ACTIVE"}}]}},` +
+		`"post_filter":{"bool":{"filter":[{"term":{"unit.keyword":"k\"g"}}]}},` +
+		`"_source":["id","name"],` +
+		`"sort":[{"sale_price":"desc"}],` +
+		`"aggs":{"units":{"terms":{"field":"unit.keyword","size":20}}}}`
+
+	body, err := req.Reader()
+	if err != nil {
+		t.Fatalf("Reader: %v", err)
+	}
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestSearchRequestOmitsEmptyParts(t *testing.T) {
+	want := `{"from":0,"size":5}`
+	if got := marshal(t, SearchRequest{Size: 5}); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{order: SortDesc, want: `{"id":"desc"}`},
+		{order: SortAsc, want: `{"id":"asc"}`},
+		{order: `desc"}`, want: `{"id":"asc"}`},
+		{order: "", want: `{"id":"asc"}`},
+	}
+
+	for _, tt := range tests {
+		if got := marshal(t, SortBy("id", tt.order)); got != tt.want {
+			t.Errorf("SortBy(%q): got %s, want %s", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestSortFieldsSort(t *testing.T) {
+	fields := SortFields{
+		"id":   "id",
+		"name": "name.keyword",
+	}
+
+	tests := []struct {
+		name      string
+		requested string
+		fallback  string
+		want      string
+	}{
+		{name: "whitelisted key", requested: "name", fallback: "id", want: `[{"name.keyword":"desc"}]`},
+		{name: "unknown key uses fallback", requested: "password", fallback: "id", want: `[{"id":"desc"}]`},
+		{name: "indexed field name is not a key", requested: "name.keyword", fallback: "id", want: `[{"id":"desc"}]`},
+		{name: "unknown fallback skips the sort", requested: "password", fallback: "created_at", want: `null`},
+		{name: "empty keys skip the sort", requested: "", fallback: "", want: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshal(t, fields.Sort(tt.requested, SortDesc, tt.fallback)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRequestWithoutResolvedSort(t *testing.T) {
+	req := SearchRequest{Size: 10, Sort: SortFields{"id": "id"}.Sort("unknown", SortAsc, "unknown")}
+
+	want := `{"from":0,"size":10}`
+	if got := marshal(t, req); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+	"user-service/internal/adapter/message"
+
+	"github.com/spf13/cobra"
+)
+
+var workerNotificationCmd = &cobra.Command{
+	Use:   "worker-notification",
+	Short: "Menjalankan worker untuk mengirim email notifikasi dari antrian user-service",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Worker untuk Notification sedang berjalan...")
+		message.StartNotificationConsumer()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerNotificationCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+	"user-service/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var workerTokenCleanupCmd = &cobra.Command{
+	Use:   "worker-token-cleanup",
+	Short: "Menjalankan worker untuk menghapus token verifikasi yang sudah kedaluwarsa atau terpakai",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Worker untuk Token Cleanup sedang berjalan...")
+		app.RunTokenCleanup()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerTokenCleanupCmd)
+}
@@ -9,7 +9,17 @@ type App struct {
 	JwtSecretKey string `json:"jwt_secret_key"`
 	JwtIssuer    string `json:"jwt_issuer"`
 
+	InternalApiKey string `json:"internal_api_key"`
+
+	AccessTokenTTL  int `json:"access_token_ttl"`
+	RefreshTokenTTL int `json:"refresh_token_ttl"`
+
 	UrlForgotPassword string `json:"url_forgot_password"`
+
+	TokenTTLEmailVerification int `json:"token_ttl_email_verification"`
+	TokenTTLResetPassword     int `json:"token_ttl_reset_password"`
+	TokenTTLInvitation        int `json:"token_ttl_invitation"`
+	TokenCleanupInterval      int `json:"token_cleanup_interval"`
 }
 
 type PsqlDB struct {
@@ -27,6 +37,9 @@ type RabbitMQ struct {
 	Port     string `json:"port"`
 	User     string `json:"user"`
 	Password string `json:"password"`
+
+	ConsumerMaxAttempts int `json:"consumer_max_attempts"`
+	ConsumerRetryDelay  int `json:"consumer_retry_delay"`
 }
 
 type Supabase struct {
@@ -40,12 +53,28 @@ type Redis struct {
 	Port string `json:"port"`
 }
 
+type Mail struct {
+	Driver   string `json:"driver"`
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	FileDir  string `json:"file_dir"`
+}
+
+type PublisherName struct {
+	CustomerChanged string `json:"customer_changed"`
+}
+
 type Config struct {
-	App      App      `json:"app"`
-	Psql     PsqlDB   `json:"psql"`
-	RabbitMQ RabbitMQ `json:"rabbitmq"`
-	Storage  Supabase `json:"storage"`
-	Redis    Redis    `json:"redis"`
+	App           App           `json:"app"`
+	Psql          PsqlDB        `json:"psql"`
+	RabbitMQ      RabbitMQ      `json:"rabbitmq"`
+	Storage       Supabase      `json:"storage"`
+	Redis         Redis         `json:"redis"`
+	Mail          Mail          `json:"mail"`
+	PublisherName PublisherName `json:"publisher_name"`
 }
 
 func NewConfig() *Config {
@@ -57,7 +86,17 @@ func NewConfig() *Config {
 			JwtSecretKey: viper.GetString("JWT_SECRET_KEY"),
 			JwtIssuer:    viper.GetString("JWT_ISSUER"),
 
+			InternalApiKey: viper.GetString("INTERNAL_API_KEY"),
+
+			AccessTokenTTL:  viper.GetInt("ACCESS_TOKEN_TTL"),
+			RefreshTokenTTL: viper.GetInt("REFRESH_TOKEN_TTL"),
+
 			UrlForgotPassword: viper.GetString("URL_FORGOT_PASSWORD"),
+
+			TokenTTLEmailVerification: viper.GetInt("TOKEN_TTL_EMAIL_VERIFICATION"),
+			TokenTTLResetPassword:     viper.GetInt("TOKEN_TTL_RESET_PASSWORD"),
+			TokenTTLInvitation:        viper.GetInt("TOKEN_TTL_INVITATION"),
+			TokenCleanupInterval:      viper.GetInt("TOKEN_CLEANUP_INTERVAL"),
 		},
 		Psql: PsqlDB{
 			Host:      viper.GetString("DATABASE_HOST"),
@@ -73,6 +112,9 @@ func NewConfig() *Config {
 			Port:     viper.GetString("RABBITMQ_PORT"),
 			User:     viper.GetString("RABBITMQ_USER"),
 			Password: viper.GetString("RABBITMQ_PASSWORD"),
+
+			ConsumerMaxAttempts: viper.GetInt("RABBITMQ_CONSUMER_MAX_ATTEMPTS"),
+			ConsumerRetryDelay:  viper.GetInt("RABBITMQ_CONSUMER_RETRY_DELAY"),
 		},
 		Storage: Supabase{
 			URL:    viper.GetString("SUPABASE_STORAGE_URL"),
@@ -83,5 +125,17 @@ func NewConfig() *Config {
 			Host: viper.GetString("REDIS_HOST"),
 			Port: viper.GetString("REDIS_PORT"),
 		},
+		Mail: Mail{
+			Driver:   viper.GetString("MAIL_DRIVER"),
+			Host:     viper.GetString("SMTP_HOST"),
+			Port:     viper.GetString("SMTP_PORT"),
+			Username: viper.GetString("SMTP_USERNAME"),
+			Password: viper.GetString("SMTP_PASSWORD"),
+			From:     viper.GetString("MAIL_FROM"),
+			FileDir:  viper.GetString("MAIL_FILE_DIR"),
+		},
+		PublisherName: PublisherName{
+			CustomerChanged: viper.GetString("CUSTOMER_CHANGED_NAME"),
+		},
 	}
 }
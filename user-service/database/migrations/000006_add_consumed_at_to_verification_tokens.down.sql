@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_verification_tokens_expires_at;
+DROP INDEX IF EXISTS idx_verification_tokens_token;
+
+ALTER TABLE verification_tokens DROP COLUMN IF EXISTS consumed_at;
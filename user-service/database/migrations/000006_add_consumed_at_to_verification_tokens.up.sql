@@ -0,0 +1,4 @@
+ALTER TABLE verification_tokens ADD COLUMN IF NOT EXISTS consumed_at TIMESTAMP NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_verification_tokens_token ON verification_tokens(token);
+CREATE INDEX IF NOT EXISTS idx_verification_tokens_expires_at ON verification_tokens(expires_at);
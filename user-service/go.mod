@@ -3,12 +3,16 @@ module user-service
 go 1.24.5
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/alicebob/miniredis/v2 v2.37.0
 	github.com/labstack/gommon v0.4.2
 	github.com/spf13/viper v1.20.1
 	golang.org/x/crypto v0.41.0
 	gorm.io/gorm v1.30.3
 )
 
+require github.com/yuin/gopher-lua v1.1.1 // indirect
+
 require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
@@ -3,8 +3,8 @@ package request
 type CustomerRequest struct {
 	Name                 string  `json:"name" validate:"required"`
 	Email                string  `json:"email" validate:"email,required"`
-	Password             string  `json:"password" validate:"required,min=8"`
-	PasswordConfirmation string  `json:"password_confirmation" validate:"required,min=8"`
+	Password             string  `json:"password" validate:"omitempty,min=8"`
+	PasswordConfirmation string  `json:"password_confirmation" validate:"omitempty,min=8"`
 	Phone                string  `json:"phone" validate:"required,number"`
 	Address              string  `json:"address"`
 	Lat                  float64 `json:"lat"`
@@ -31,3 +31,7 @@ type UpdateDataUserRequest struct {
 	Lng     string `json:"lng" validate:"required"`
 	Photo   string `json:"photo" validate:"required"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
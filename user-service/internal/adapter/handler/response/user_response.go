@@ -1,14 +1,16 @@
 package response
 
 type SignInResponse struct {
-	AccessToken string `json:"access_token"`
-	Role        string `json:"role"`
-	ID          int64  `json:"id"`
-	Name        string `json:"name"`
-	Email       string `json:"email"`
-	Phone       string `json:"phone"`
-	Lat         string `json:"lat"`
-	Lng         string `json:"lng"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Role         string `json:"role"`
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Lat          string `json:"lat"`
+	Lng          string `json:"lng"`
 }
 
 type ProfileResponse struct {
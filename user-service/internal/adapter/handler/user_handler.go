@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"user-service/config"
 	"user-service/internal/adapter"
 	"user-service/internal/adapter/handler/request"
@@ -17,14 +18,20 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
+// maxCustomerIDs caps the number of customers fetched in one ?ids= request.
+const maxCustomerIDs = 100
+
 type UserHandlerInterface interface {
 	SignIn(c echo.Context) error
+	RefreshToken(c echo.Context) error
 	CreateUserAccount(c echo.Context) error
 	ForgotPassword(c echo.Context) error
 	VerifyAccount(c echo.Context) error
 	UpdatePassword(c echo.Context) error
 	GetProfileUser(c echo.Context) error
 	UpdateDataUser(c echo.Context) error
+	Logout(c echo.Context) error
+	LogoutAll(c echo.Context) error
 
 	// Modul Customers Admin
 	GetCustomerAll(c echo.Context) error
@@ -32,10 +39,13 @@ type UserHandlerInterface interface {
 	CreateCustomer(c echo.Context) error
 	UpdateCustomer(c echo.Context) error
 	DeleteCustomer(c echo.Context) error
+
+	GetCustomersByIDsInternal(c echo.Context) error
 }
 
 type userHandler struct {
 	userService service.UserServiceInterface
+	jwtService  service.JwtServiceInterface
 }
 
 // DeleteCustomer implements UserHandlerInterface.
@@ -117,31 +127,30 @@ func (u *userHandler) CreateCustomer(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, resp)
 	}
 
-	if req.Password != req.PasswordConfirmation {
-		log.Infof("[UserHandler-4] CreateCustomer: %s", "password and confirm password does not match")
-		resp.Message = "password and confirm password does not match"
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
-	}
-
 	latString := strconv.FormatFloat(req.Lat, 'g', -1, 64)
 	lngString := strconv.FormatFloat(req.Lng, 'g', -1, 64)
 
+	// The customer sets their own password through the invitation email, so a
+	// password in the request is ignored.
 	reqEntity := entity.UserEntity{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: req.Password,
-		Phone:    req.Phone,
-		Address:  req.Address,
-		Lat:      latString,
-		Lng:      lngString,
-		Photo:    req.Photo,
-		RoleID:   req.RoleID,
+		Name:    req.Name,
+		Email:   req.Email,
+		Phone:   req.Phone,
+		Address: req.Address,
+		Lat:     latString,
+		Lng:     lngString,
+		Photo:   req.Photo,
+		RoleID:  req.RoleID,
 	}
 
 	err = u.userService.CreateCustomer(ctx, reqEntity)
 	if err != nil {
-		log.Fatalf("[UserHandler-5] CreateCustomer: %v", err)
+		log.Errorf("[UserHandler-4] CreateCustomer: %v", err)
+		if err.Error() == "422" {
+			resp.Message = "Role not found"
+			resp.Data = nil
+			return c.JSON(http.StatusUnprocessableEntity, resp)
+		}
 		resp.Message = "failed to create customer"
 		resp.Data = nil
 		return c.JSON(http.StatusInternalServerError, resp)
@@ -184,6 +193,13 @@ func (u *userHandler) UpdateCustomer(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, resp)
 	}
 
+	if req.Password != req.PasswordConfirmation {
+		log.Infof("[UserHandler-4] UpdateCustomer: %s", "password and confirm password does not match")
+		resp.Message = "password and confirm password does not match"
+		resp.Data = nil
+		return c.JSON(http.StatusUnprocessableEntity, resp)
+	}
+
 	latString := ""
 	lngString := ""
 	if req.Lat != 0 {
@@ -197,7 +213,7 @@ func (u *userHandler) UpdateCustomer(c echo.Context) error {
 
 	idParamStr := c.Param("id")
 	if idParamStr == "" {
-		log.Infof("[UserHandler-4] UpdateCustomer: %s", "missing or invalid customer ID")
+		log.Infof("[UserHandler-5] UpdateCustomer: %s", "missing or invalid customer ID")
 		resp.Message = "missing or invalid customer ID"
 		resp.Data = nil
 		return c.JSON(http.StatusBadRequest, resp)
@@ -205,7 +221,7 @@ func (u *userHandler) UpdateCustomer(c echo.Context) error {
 
 	id, err := conv.StringToInt64(idParamStr)
 	if err != nil {
-		log.Infof("[UserHandler-5] UpdateCustomer: %s", "invalid customer ID")
+		log.Infof("[UserHandler-6] UpdateCustomer: %s", "invalid customer ID")
 		resp.Message = "invalid customer ID"
 		resp.Data = nil
 		return c.JSON(http.StatusBadRequest, resp)
@@ -221,16 +237,22 @@ func (u *userHandler) UpdateCustomer(c echo.Context) error {
 		Lat:      latString,
 		Lng:      lngString,
 		Photo:    req.Photo,
+		RoleID:   req.RoleID,
 	}
 
-	err = u.userService.UpdateDataUser(ctx, reqEntity)
+	err = u.userService.UpdateCustomer(ctx, reqEntity)
 	if err != nil {
-		log.Errorf("[UserHandler-6] UpdateCustomer: %v", err)
+		log.Errorf("[UserHandler-7] UpdateCustomer: %v", err)
 		if err.Error() == "404" {
 			resp.Message = "Customer not found"
 			resp.Data = nil
 			return c.JSON(http.StatusNotFound, resp)
 		}
+		if err.Error() == "422" {
+			resp.Message = "Role not found"
+			resp.Data = nil
+			return c.JSON(http.StatusUnprocessableEntity, resp)
+		}
 		resp.Message = err.Error()
 		resp.Data = nil
 		return c.JSON(http.StatusInternalServerError, resp)
@@ -320,6 +342,10 @@ func (u *userHandler) GetCustomerAll(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, resp)
 	}
 
+	if idsParam := c.QueryParam("ids"); idsParam != "" {
+		return u.getCustomersByIDs(c, idsParam)
+	}
+
 	search := c.QueryParam("search")
 	orderBy := c.QueryParam("order_by")
 	orderType := c.QueryParam("order_type")
@@ -385,6 +411,63 @@ func (u *userHandler) GetCustomerAll(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// GetCustomersByIDsInternal implements UserHandlerInterface.
+// Other services read buyer details from GET /internal/customers?ids=1,2,3.
+func (u *userHandler) GetCustomersByIDsInternal(c echo.Context) error {
+	return u.getCustomersByIDs(c, c.QueryParam("ids"))
+}
+
+// getCustomersByIDs answers GET /admin/customers?ids=1,2,3 with the customers
+// that exist among the given IDs, without pagination.
+func (u *userHandler) getCustomersByIDs(c echo.Context, idsParam string) error {
+	var (
+		resp     = response.DefaultResponse{}
+		ctx      = c.Request().Context()
+		respUser = []response.CustomerResponse{}
+	)
+
+	ids, err := conv.StringToInt64List(idsParam)
+	if err != nil {
+		log.Errorf("[UserHandler-1] getCustomersByIDs: %v", err)
+		resp.Message = "ids must be a comma separated list of numbers"
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	if len(ids) > maxCustomerIDs {
+		log.Infof("[UserHandler-2] getCustomersByIDs: %d ids requested", len(ids))
+		resp.Message = "too many ids, maximum is " + strconv.Itoa(maxCustomerIDs)
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	results, err := u.userService.GetCustomersByIDs(ctx, ids)
+	if err != nil {
+		log.Errorf("[UserHandler-3] getCustomersByIDs: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	for _, val := range results {
+		respUser = append(respUser, response.CustomerResponse{
+			ID:      val.ID,
+			RoleID:  val.RoleID,
+			Name:    val.Name,
+			Email:   val.Email,
+			Phone:   val.Phone,
+			Address: val.Address,
+			Photo:   val.Photo,
+			Lat:     val.Lat,
+			Lng:     val.Lng,
+		})
+	}
+
+	resp.Message = "Data retrieved successfully"
+	resp.Data = respUser
+	return c.JSON(http.StatusOK, resp)
+}
+
 // UpdateDataUser implements UserHandlerInterface.
 func (u *userHandler) UpdateDataUser(c echo.Context) error {
 	var (
@@ -457,6 +540,85 @@ func (u *userHandler) UpdateDataUser(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// Logout implements UserHandlerInterface.
+// Only the session of the token used for this request is ended, together with
+// the refresh token issued with it.
+func (u *userHandler) Logout(c echo.Context) error {
+	var (
+		resp        = response.DefaultResponse{}
+		ctx         = c.Request().Context()
+		jwtUserData = entity.JwtUserData{}
+	)
+
+	user := c.Get("user").(string)
+	if user == "" {
+		log.Errorf("[UserHandler-1] Logout: %s", "data token not found")
+		resp.Message = "data token not found"
+		resp.Data = nil
+		return c.JSON(http.StatusNotFound, resp)
+	}
+
+	err := json.Unmarshal([]byte(user), &jwtUserData)
+	if err != nil {
+		log.Errorf("[UserHandler-2] Logout: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	// The session to end is the one of the token used for this request.
+	jwtUserData.Token = strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	err = u.userService.Logout(ctx, jwtUserData)
+	if err != nil {
+		log.Errorf("[UserHandler-3] Logout: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	resp.Message = "Logged out successfully"
+	resp.Data = nil
+	return c.JSON(http.StatusOK, resp)
+}
+
+// LogoutAll implements UserHandlerInterface.
+// Every session of the user ends, on all devices.
+func (u *userHandler) LogoutAll(c echo.Context) error {
+	var (
+		resp        = response.DefaultResponse{}
+		ctx         = c.Request().Context()
+		jwtUserData = entity.JwtUserData{}
+	)
+
+	user := c.Get("user").(string)
+	if user == "" {
+		log.Errorf("[UserHandler-1] LogoutAll: %s", "data token not found")
+		resp.Message = "data token not found"
+		resp.Data = nil
+		return c.JSON(http.StatusNotFound, resp)
+	}
+
+	err := json.Unmarshal([]byte(user), &jwtUserData)
+	if err != nil {
+		log.Errorf("[UserHandler-2] LogoutAll: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	err = u.userService.LogoutAll(ctx, jwtUserData.UserID)
+	if err != nil {
+		log.Errorf("[UserHandler-3] LogoutAll: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	resp.Message = "Logged out from all sessions successfully"
+	resp.Data = nil
+	return c.JSON(http.StatusOK, resp)
+}
+
 // GetProfileUser implements UserHandlerInterface.
 func (u *userHandler) GetProfileUser(c echo.Context) error {
 	var (
@@ -623,6 +785,8 @@ func (u *userHandler) VerifyAccount(c echo.Context) error {
 	respSignIn.Lng = user.Lng
 	respSignIn.Phone = user.Phone
 	respSignIn.AccessToken = user.Token
+	respSignIn.RefreshToken = user.RefreshToken
+	respSignIn.ExpiresIn = int64(u.jwtService.AccessTokenTTL().Seconds())
 
 	resp.Message = "Success"
 	resp.Data = respSignIn
@@ -759,7 +923,14 @@ func (u *userHandler) SignIn(c echo.Context) error {
 			resp.Data = nil
 			return c.JSON(http.StatusNotFound, resp)
 		}
-		log.Errorf("[UserHandler-4] SignIn: %v", err)
+
+		if err.Error() == "403" {
+			log.Infof("[UserHandler-4] SignIn: %s", "password reset required")
+			resp.Message = "Password reset required, a link to set a new password has been sent to your email"
+			resp.Data = nil
+			return c.JSON(http.StatusForbidden, resp)
+		}
+		log.Errorf("[UserHandler-5] SignIn: %v", err)
 		resp.Message = err.Error()
 		resp.Data = nil
 		return c.JSON(http.StatusInternalServerError, resp)
@@ -773,6 +944,62 @@ func (u *userHandler) SignIn(c echo.Context) error {
 	respSignIn.Lng = user.Lng
 	respSignIn.Phone = user.Phone
 	respSignIn.AccessToken = token
+	respSignIn.RefreshToken = user.RefreshToken
+	respSignIn.ExpiresIn = int64(u.jwtService.AccessTokenTTL().Seconds())
+
+	resp.Message = "Success"
+	resp.Data = respSignIn
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RefreshToken implements UserHandlerInterface.
+func (u *userHandler) RefreshToken(c echo.Context) error {
+	var (
+		req        = request.RefreshTokenRequest{}
+		resp       = response.DefaultResponse{}
+		respSignIn = response.SignInResponse{}
+		ctx        = c.Request().Context()
+	)
+
+	if err = c.Bind(&req); err != nil {
+		log.Errorf("[UserHandler-1] RefreshToken: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusUnprocessableEntity, resp)
+	}
+
+	if err = c.Validate(req); err != nil {
+		log.Errorf("[UserHandler-2] RefreshToken: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusUnprocessableEntity, resp)
+	}
+
+	user, err := u.userService.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		if err.Error() == "401" {
+			log.Infof("[UserHandler-3] RefreshToken: %s", "refresh token expired or invalid")
+			resp.Message = "Refresh token expired or invalid"
+			resp.Data = nil
+			return c.JSON(http.StatusUnauthorized, resp)
+		}
+		log.Errorf("[UserHandler-4] RefreshToken: %v", err)
+		resp.Message = err.Error()
+		resp.Data = nil
+		return c.JSON(http.StatusInternalServerError, resp)
+	}
+
+	respSignIn.ID = user.ID
+	respSignIn.Name = user.Name
+	respSignIn.Email = user.Email
+	respSignIn.Role = user.RoleName
+	respSignIn.Lat = user.Lat
+	respSignIn.Lng = user.Lng
+	respSignIn.Phone = user.Phone
+	respSignIn.AccessToken = user.Token
+	respSignIn.RefreshToken = user.RefreshToken
+	respSignIn.ExpiresIn = int64(u.jwtService.AccessTokenTTL().Seconds())
 
 	resp.Message = "Success"
 	resp.Data = respSignIn
@@ -781,10 +1008,11 @@ func (u *userHandler) SignIn(c echo.Context) error {
 }
 
 func NewUserHandler(e *echo.Echo, userService service.UserServiceInterface, cfg *config.Config, jwtService service.JwtServiceInterface) UserHandlerInterface {
-	userHandler := &userHandler{userService: userService}
+	userHandler := &userHandler{userService: userService, jwtService: jwtService}
 
 	e.Use(middleware.Recover())
 	e.POST("/signin", userHandler.SignIn)
+	e.POST("/refresh-token", userHandler.RefreshToken)
 	e.POST("/signup", userHandler.CreateUserAccount)
 	e.POST("/forgot-password", userHandler.ForgotPassword)
 	e.GET("/verify-account", userHandler.VerifyAccount)
@@ -804,6 +1032,11 @@ func NewUserHandler(e *echo.Echo, userService service.UserServiceInterface, cfg
 	authGroup := e.Group("/auth", mid.CheckToken())
 	authGroup.GET("/profile", userHandler.GetProfileUser)
 	authGroup.PUT("/profile", userHandler.UpdateDataUser)
+	authGroup.POST("/logout", userHandler.Logout)
+	authGroup.POST("/logout-all", userHandler.LogoutAll)
+
+	internalGroup := e.Group("/internal", mid.CheckInternalKey())
+	internalGroup.GET("/customers", userHandler.GetCustomersByIDsInternal)
 
 	return userHandler
 }
@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+	"user-service/config"
+
+	"github.com/labstack/gommon/log"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// fileMailer is the local sink: every email is written as an .eml file to dir,
+// or only logged when dir is empty.
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// Send implements MailerInterface.
+func (f *fileMailer) Send(ctx context.Context, mail Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if f.dir == "" {
+		log.Infof("[FileMailer-1] Send: to=%s subject=%q\n%s", mail.To, mail.Subject, mail.Text)
+		return nil
+	}
+
+	msg, err := buildMessage(f.from, mail)
+	if err != nil {
+		log.Errorf("[FileMailer-2] Send: %v", err)
+		return err
+	}
+
+	if err = os.MkdirAll(f.dir, 0o755); err != nil {
+		log.Errorf("[FileMailer-3] Send: %v", err)
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(mail.To, "_"))
+	path := filepath.Join(f.dir, name)
+	if err = os.WriteFile(path, msg, 0o644); err != nil {
+		log.Errorf("[FileMailer-4] Send: %v", err)
+		return err
+	}
+
+	log.Infof("[FileMailer-5] Send: email to %s written to %s", mail.To, path)
+	return nil
+}
+
+func NewFileMailer(cfg *config.Config) MailerInterface {
+	return &fileMailer{dir: cfg.Mail.FileDir, from: cfg.Mail.From}
+}
@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"user-service/config"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+)
+
+// Mail is a rendered email ready to be sent.
+type Mail struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// MailerInterface delivers rendered emails. MAIL_DRIVER picks the implementation.
+type MailerInterface interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// NewMailer returns the SMTP mailer when MAIL_DRIVER is smtp and the file sink
+// otherwise, so local environments never send real emails by accident.
+func NewMailer(cfg *config.Config) MailerInterface {
+	if strings.ToLower(cfg.Mail.Driver) == DriverSMTP {
+		return NewSMTPMailer(cfg)
+	}
+
+	return NewFileMailer(cfg)
+}
@@ -0,0 +1,191 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"time"
+	"user-service/config"
+
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	smtpDialTimeout = 10 * time.Second
+	smtpSendTimeout = time.Minute
+)
+
+type smtpMailer struct {
+	host string
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// Send implements MailerInterface.
+// It follows smtp.SendMail, upgrading the connection with STARTTLS when the
+// server offers it, but dials with a timeout and bounds the whole exchange by
+// smtpSendTimeout or the deadline of ctx, whichever comes first. Cancelling ctx
+// closes the connection.
+func (s *smtpMailer) Send(ctx context.Context, mail Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg, err := buildMessage(s.from, mail)
+	if err != nil {
+		log.Errorf("[SMTPMailer-1] Send: %v", err)
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: smtpDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		log.Errorf("[SMTPMailer-2] Send: %v", err)
+		return err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(smtpSendTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		log.Errorf("[SMTPMailer-3] Send: %v", err)
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	if err = s.send(conn, mail.To, msg); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		log.Errorf("[SMTPMailer-4] Send: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// send runs the SMTP exchange for one message over conn.
+func (s *smtpMailer) send(conn net.Conn, to string, msg []byte) error {
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+
+	if s.auth != nil {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err = client.Auth(s.auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = client.Mail(s.from); err != nil {
+		return err
+	}
+
+	if err = client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildMessage writes mail as a multipart/alternative message with the text
+// part first, so clients that cannot show HTML fall back to it.
+func buildMessage(from string, mail Mail) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mail.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", mail.Text},
+		{"text/html", mail.HTML},
+	}
+
+	for _, part := range parts {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err = qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err = qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func NewSMTPMailer(cfg *config.Config) MailerInterface {
+	var auth smtp.Auth
+	if cfg.Mail.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.Host)
+	}
+
+	return &smtpMailer{
+		host: cfg.Mail.Host,
+		addr: net.JoinHostPort(cfg.Mail.Host, cfg.Mail.Port),
+		from: cfg.Mail.From,
+		auth: auth,
+	}
+}
@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"user-service/utils"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// subjects lists every notification type that has templates; the HTML and
+// text bodies live in templates/<type>.html and templates/<type>.txt.
+var subjects = map[string]string{
+	utils.NOTIF_EMAIL_VERIFICATION:    "Verify your email address",
+	utils.NOTIF_EMAIL_FORGOT_PASSWORD: "Reset your password",
+	utils.NOTIF_EMAIL_CREATE_CUSTOMER: "Your Sayur Project account",
+	utils.NOTIF_EMAIL_UPDATE_CUSTOMER: "Your Sayur Project account has been updated",
+}
+
+// TemplateData is what the notification templates can use.
+type TemplateData struct {
+	Subject string
+	Email   string
+	Message string
+}
+
+type notificationTemplate struct {
+	subject string
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// RendererInterface turns a queued notification into a Mail.
+type RendererInterface interface {
+	Render(notifType, email, message string) (Mail, error)
+}
+
+type renderer struct {
+	templates map[string]notificationTemplate
+}
+
+// Render implements RendererInterface.
+func (r *renderer) Render(notifType, email, message string) (Mail, error) {
+	tmpl, ok := r.templates[notifType]
+	if !ok {
+		return Mail{}, fmt.Errorf("no template for notification type %q", notifType)
+	}
+
+	data := TemplateData{Subject: tmpl.subject, Email: email, Message: message}
+
+	var html bytes.Buffer
+	if err := tmpl.html.ExecuteTemplate(&html, "layout.html", data); err != nil {
+		return Mail{}, err
+	}
+
+	var text bytes.Buffer
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return Mail{}, err
+	}
+
+	return Mail{
+		To:      email,
+		Subject: tmpl.subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// NewRenderer parses the embedded templates of every notification type, so a
+// broken template stops the worker at startup instead of failing per message.
+func NewRenderer() (RendererInterface, error) {
+	templates := map[string]notificationTemplate{}
+	for notifType, subject := range subjects {
+		html, err := htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+notifType+".html")
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := texttemplate.ParseFS(templateFS, "templates/"+notifType+".txt")
+		if err != nil {
+			return nil, err
+		}
+
+		templates[notifType] = notificationTemplate{subject: subject, html: html, text: text}
+	}
+
+	return &renderer{templates: templates}, nil
+}
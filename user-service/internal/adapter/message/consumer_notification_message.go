@@ -0,0 +1,97 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"user-service/config"
+	"user-service/internal/adapter/mailer"
+	"user-service/internal/core/domain/entity"
+	"user-service/utils"
+
+	"github.com/labstack/gommon/log"
+)
+
+// notificationQueues are the queues PublishMessage writes to; each one has its
+// own templates in the mailer package.
+var notificationQueues = []string{
+	utils.NOTIF_EMAIL_VERIFICATION,
+	utils.NOTIF_EMAIL_FORGOT_PASSWORD,
+	utils.NOTIF_EMAIL_CREATE_CUSTOMER,
+	utils.NOTIF_EMAIL_UPDATE_CUSTOMER,
+}
+
+// StartNotificationConsumer renders and sends the emails queued by the user
+// service. Each queue is consumed on its own channel; the worker stops when
+// any consumer stops.
+func StartNotificationConsumer() {
+	cfg := config.NewConfig()
+
+	renderer, err := mailer.NewRenderer()
+	if err != nil {
+		log.Errorf("[StartNotificationConsumer-1] Failed to parse templates: %v", err)
+		return
+	}
+
+	sender := mailer.NewMailer(cfg)
+
+	conn, err := cfg.NewRabbitMQ()
+	if err != nil {
+		log.Errorf("[StartNotificationConsumer-2] Failed to connect to RabbitMQ: %v", err)
+		return
+	}
+
+	defer conn.Close()
+
+	errCh := make(chan error, len(notificationQueues))
+	for _, queueName := range notificationQueues {
+		ch, err := conn.Channel()
+		if err != nil {
+			log.Errorf("[StartNotificationConsumer-3] Failed to open a channel: %v", err)
+			return
+		}
+		defer ch.Close()
+
+		go func(queueName string) {
+			errCh <- consumeWithRetry(ch, cfg, queueName, func(body []byte) error {
+				return sendNotification(renderer, sender, queueName, body)
+			})
+		}(queueName)
+	}
+
+	log.Info("RabbitMQ Consumer notification started...")
+
+	for range notificationQueues {
+		if err = <-errCh; err != nil {
+			log.Fatalf("[StartNotificationConsumer-4] Consumer stopped: %v", err)
+		}
+	}
+}
+
+func sendNotification(renderer mailer.RendererInterface, sender mailer.MailerInterface, notifType string, body []byte) error {
+	var notification entity.NotificationEntity
+	if err := json.Unmarshal(body, &notification); err != nil {
+		log.Errorf("[sendNotification-1] Error decoding message: %v", err)
+		return permanent(err)
+	}
+
+	if notification.Email == "" {
+		err := errors.New("notification has no recipient")
+		log.Errorf("[sendNotification-2] %v", err)
+		return permanent(err)
+	}
+
+	mail, err := renderer.Render(notifType, notification.Email, notification.Message)
+	if err != nil {
+		log.Errorf("[sendNotification-3] %v", err)
+		return permanent(err)
+	}
+
+	if err = sender.Send(context.Background(), mail); err != nil {
+		log.Errorf("[sendNotification-4] %v", err)
+		return err
+	}
+
+	log.Infof("[sendNotification-5] Email %s dikirim ke %s", notifType, notification.Email)
+	return nil
+}
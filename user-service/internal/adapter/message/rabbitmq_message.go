@@ -3,59 +3,80 @@ package message
 import (
 	"encoding/json"
 	"user-service/config"
+	"user-service/internal/core/domain/entity"
 
 	"github.com/labstack/gommon/log"
 	"github.com/streadway/amqp"
 )
 
-func PublishMessage(email, message, notif_type string) error {
-	conn, err := config.NewConfig().NewRabbitMQ()
+type PublishRabbitMQInterface interface {
+	PublishMessage(email, message, notifType string) error
+	PublishCustomerChanged(customerID int64) error
+}
+
+type PublishRabbitMQ struct {
+	cfg       *config.Config
+	publisher RabbitMQPublisherInterface
+}
+
+// PublishMessage implements PublishRabbitMQInterface.
+func (p *PublishRabbitMQ) PublishMessage(email, message, notifType string) error {
+	notification := entity.NotificationEntity{
+		Email:   email,
+		Message: message,
+	}
+
+	body, err := json.Marshal(notification)
 	if err != nil {
-		log.Errorf("[PublishMessage-1] Failed to connect to RabbitMQ: %v", err)
+		log.Errorf("[PublishMessage-1] Failed to marshal JSON: %v", err)
 		return err
 	}
-	defer conn.Close()
 
-	ch, err := conn.Channel()
+	err = p.publisher.Publish(notifType, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
 	if err != nil {
-		log.Errorf("[PublishMessage-2] Failed to open a channel: %v", err)
+		log.Errorf("[PublishMessage-2] Failed to publish message: %v", err)
 		return err
 	}
-	defer ch.Close()
 
-	queue, err := ch.QueueDeclare(
-		notif_type,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+	return nil
+}
 
-	if err != nil {
-		log.Errorf("[PublishMessage-3] Failed to declare a queue: %v", err)
-		return err
+// PublishCustomerChanged implements PublishRabbitMQInterface.
+// order-service listens for it to drop its cached copy of the customer. Nothing
+// is sent when no queue is configured, which NewPublishRabbitMQ reports at
+// startup.
+func (p *PublishRabbitMQ) PublishCustomerChanged(customerID int64) error {
+	if p.cfg.PublisherName.CustomerChanged == "" {
+		return nil
 	}
 
-	notification := map[string]string{
-		"email":   email,
-		"message": message,
+	body, err := json.Marshal(map[string]int64{"customer_id": customerID})
+	if err != nil {
+		log.Errorf("[PublishCustomerChanged-1] Failed to marshal JSON: %v", err)
+		return err
 	}
 
-	body, err := json.Marshal(notification)
+	err = p.publisher.Publish(p.cfg.PublisherName.CustomerChanged, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
 	if err != nil {
-		log.Errorf("[PublishMessage-4] Failed to marshal JSON: %v", err)
+		log.Errorf("[PublishCustomerChanged-2] Failed to publish message: %v", err)
 		return err
 	}
 
-	return ch.Publish(
-		"",
-		queue.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	return nil
+}
+
+func NewPublishRabbitMQ(cfg *config.Config, publisher RabbitMQPublisherInterface) PublishRabbitMQInterface {
+	if cfg.PublisherName.CustomerChanged == "" {
+		log.Errorf("[NewPublishRabbitMQ-1] CUSTOMER_CHANGED_NAME is not set, order-service will not be told about customer changes")
+	}
+
+	return &PublishRabbitMQ{cfg: cfg, publisher: publisher}
 }
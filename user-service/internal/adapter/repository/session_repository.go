@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"user-service/internal/core/domain/entity"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/gommon/log"
+)
+
+// ErrRefreshTokenReused is returned by ConsumeRefreshToken for a refresh token
+// that was already rotated, which means it has been copied.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+// consumeRefreshScript swaps a refresh token for a "used" marker that lives as
+// long as the token would have, in one step so the same token can never be
+// consumed twice. It returns {1, data} for a fresh token, {2, data} for a token
+// that was already used and {0} for an unknown or expired one.
+var consumeRefreshScript = redis.NewScript(`
+local data = redis.call('GET', KEYS[1])
+if data then
+	local ttl = redis.call('PTTL', KEYS[1])
+	redis.call('DEL', KEYS[1])
+	if ttl > 0 then
+		redis.call('SET', KEYS[2], data, 'PX', ttl)
+	end
+	return {1, data}
+end
+
+local used = redis.call('GET', KEYS[2])
+if used then
+	return {2, used}
+end
+
+return {0}
+`)
+
+// deleteRefreshFamilyScript removes a family together with its current refresh
+// token and its entry in the user's index in one step, so a rotation running at
+// the same time cannot leave a valid token behind. ARGV[2] is the refresh token
+// key prefix the family's hash is appended to.
+var deleteRefreshFamilyScript = redis.NewScript(`
+local hash = redis.call('GET', KEYS[1])
+if hash then
+	redis.call('DEL', ARGV[2] .. hash)
+end
+
+redis.call('DEL', KEYS[1])
+redis.call('SREM', KEYS[2], ARGV[1])
+return 1
+`)
+
+// SessionRepositoryInterface stores login sessions in Redis keyed by the raw
+// access token, which is what CheckToken looks up in every service. Each user
+// also has an index set of their session keys so all of them can be revoked at
+// once.
+//
+// Refresh tokens are stored by their SHA-256 hash. The tokens rotated from one
+// sign in form a family that only has one valid token at a time, and each user
+// has an index set of their families.
+type SessionRepositoryInterface interface {
+	CreateSession(ctx context.Context, token string, session entity.JwtUserData, ttl time.Duration) error
+	DeleteSession(ctx context.Context, userID int64, token string) error
+	DeleteUserSessions(ctx context.Context, userID int64) (int64, error)
+
+	CreateRefreshToken(ctx context.Context, token string, refresh entity.RefreshTokenEntity) error
+	ConsumeRefreshToken(ctx context.Context, token string) (*entity.RefreshTokenEntity, error)
+	DeleteRefreshFamily(ctx context.Context, userID int64, familyID string) error
+}
+
+type sessionRepository struct {
+	redisClient *redis.Client
+}
+
+// CreateSession implements SessionRepositoryInterface.
+// The index set lives as long as the newest session, so it never outlives the
+// sessions it points to by more than one TTL.
+func (s *sessionRepository) CreateSession(ctx context.Context, token string, session entity.JwtUserData, ttl time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		log.Errorf("[SessionRepository-1] CreateSession: %v", err)
+		return err
+	}
+
+	indexKey := userSessionsKey(session.UserID)
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, token, data, ttl)
+	pipe.SAdd(ctx, indexKey, token)
+	pipe.Expire(ctx, indexKey, ttl)
+	if _, err = pipe.Exec(ctx); err != nil {
+		log.Errorf("[SessionRepository-2] CreateSession: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSession implements SessionRepositoryInterface.
+func (s *sessionRepository) DeleteSession(ctx context.Context, userID int64, token string) error {
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, token)
+	pipe.SRem(ctx, userSessionsKey(userID), token)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Errorf("[SessionRepository-1] DeleteSession: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUserSessions implements SessionRepositoryInterface.
+// Refresh token families are revoked as well so the user cannot get a new
+// access token either. Only the tokens read from the index are removed from
+// it, so a session created at the same moment stays tracked. It returns the
+// number of sessions that were still active.
+func (s *sessionRepository) DeleteUserSessions(ctx context.Context, userID int64) (int64, error) {
+	families, err := s.redisClient.SMembers(ctx, userRefreshFamiliesKey(userID)).Result()
+	if err != nil {
+		log.Errorf("[SessionRepository-1] DeleteUserSessions: %v", err)
+		return 0, err
+	}
+
+	for _, familyID := range families {
+		if err = s.DeleteRefreshFamily(ctx, userID, familyID); err != nil {
+			log.Errorf("[SessionRepository-2] DeleteUserSessions: %v", err)
+			return 0, err
+		}
+	}
+
+	indexKey := userSessionsKey(userID)
+	tokens, err := s.redisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		log.Errorf("[SessionRepository-3] DeleteUserSessions: %v", err)
+		return 0, err
+	}
+
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	members := []interface{}{}
+	for _, token := range tokens {
+		members = append(members, token)
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	deleted := pipe.Del(ctx, tokens...)
+	pipe.SRem(ctx, indexKey, members...)
+	if _, err = pipe.Exec(ctx); err != nil {
+		log.Errorf("[SessionRepository-4] DeleteUserSessions: %v", err)
+		return 0, err
+	}
+
+	return deleted.Val(), nil
+}
+
+// CreateRefreshToken implements SessionRepositoryInterface.
+// The token becomes the only valid one of its family.
+func (s *sessionRepository) CreateRefreshToken(ctx context.Context, token string, refresh entity.RefreshTokenEntity) error {
+	data, err := json.Marshal(refresh)
+	if err != nil {
+		log.Errorf("[SessionRepository-1] CreateRefreshToken: %v", err)
+		return err
+	}
+
+	ttl := time.Until(refresh.ExpiresAt)
+	hash := hashRefreshToken(token)
+	indexKey := userRefreshFamiliesKey(refresh.UserID)
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, refreshTokenKey(hash), data, ttl)
+	pipe.Set(ctx, refreshFamilyKey(refresh.FamilyID), hash, ttl)
+	pipe.SAdd(ctx, indexKey, refresh.FamilyID)
+	pipe.Expire(ctx, indexKey, ttl)
+	if _, err = pipe.Exec(ctx); err != nil {
+		log.Errorf("[SessionRepository-2] CreateRefreshToken: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ConsumeRefreshToken implements SessionRepositoryInterface.
+// It returns "401" for an unknown or expired token, and the stored data
+// together with ErrRefreshTokenReused for a token that was already used.
+func (s *sessionRepository) ConsumeRefreshToken(ctx context.Context, token string) (*entity.RefreshTokenEntity, error) {
+	hash := hashRefreshToken(token)
+	result, err := consumeRefreshScript.Run(ctx, s.redisClient, []string{refreshTokenKey(hash), usedRefreshTokenKey(hash)}).Slice()
+	if err != nil {
+		log.Errorf("[SessionRepository-1] ConsumeRefreshToken: %v", err)
+		return nil, err
+	}
+
+	status, _ := result[0].(int64)
+	if status == 0 {
+		return nil, errors.New("401")
+	}
+
+	raw, _ := result[1].(string)
+	refresh := entity.RefreshTokenEntity{}
+	if err = json.Unmarshal([]byte(raw), &refresh); err != nil {
+		log.Errorf("[SessionRepository-2] ConsumeRefreshToken: %v", err)
+		return nil, err
+	}
+
+	if status == 2 {
+		return &refresh, ErrRefreshTokenReused
+	}
+
+	return &refresh, nil
+}
+
+// DeleteRefreshFamily implements SessionRepositoryInterface.
+func (s *sessionRepository) DeleteRefreshFamily(ctx context.Context, userID int64, familyID string) error {
+	keys := []string{refreshFamilyKey(familyID), userRefreshFamiliesKey(userID)}
+	if err := deleteRefreshFamilyScript.Run(ctx, s.redisClient, keys, familyID, refreshTokenKey("")).Err(); err != nil {
+		log.Errorf("[SessionRepository-1] DeleteRefreshFamily: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func userSessionsKey(userID int64) string {
+	return fmt.Sprintf("user-service:sessions:%d", userID)
+}
+
+func userRefreshFamiliesKey(userID int64) string {
+	return fmt.Sprintf("user-service:refresh-families:%d", userID)
+}
+
+func refreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("user-service:refresh-family:%s", familyID)
+}
+
+func refreshTokenKey(hash string) string {
+	return fmt.Sprintf("user-service:refresh:%s", hash)
+}
+
+func usedRefreshTokenKey(hash string) string {
+	return fmt.Sprintf("user-service:refresh-used:%s", hash)
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewSessionRepository(redisClient *redis.Client) SessionRepositoryInterface {
+	return &sessionRepository{redisClient: redisClient}
+}
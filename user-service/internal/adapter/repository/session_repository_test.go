@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"user-service/internal/core/domain/entity"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestSessionRepository(t *testing.T) (*sessionRepository, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return &sessionRepository{redisClient: client}, server
+}
+
+func TestConsumeRefreshToken(t *testing.T) {
+	refresh := entity.RefreshTokenEntity{
+		UserID:    7,
+		FamilyID:  "family-1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name     string
+		consume  []string
+		wantErr  error
+		wantUser int64
+	}{
+		{name: "fresh token", consume: []string{"token-a"}, wantUser: 7},
+		{name: "token used twice", consume: []string{"token-a", "token-a"}, wantErr: ErrRefreshTokenReused, wantUser: 7},
+		{name: "unknown token", consume: []string{"token-b"}, wantErr: errors.New("401")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, _ := newTestSessionRepository(t)
+			if err := repo.CreateRefreshToken(ctx, "token-a", refresh); err != nil {
+				t.Fatalf("CreateRefreshToken: %v", err)
+			}
+
+			var (
+				got *entity.RefreshTokenEntity
+				err error
+			)
+			for _, token := range tt.consume {
+				got, err = repo.ConsumeRefreshToken(ctx, token)
+			}
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantUser == 0 {
+				return
+			}
+			if got == nil || got.UserID != tt.wantUser || got.FamilyID != refresh.FamilyID {
+				t.Errorf("got %+v, want user %d of family %s", got, tt.wantUser, refresh.FamilyID)
+			}
+		})
+	}
+}
+
+func TestConsumeRefreshTokenAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestSessionRepository(t)
+	refresh := entity.RefreshTokenEntity{UserID: 7, FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour)}
+
+	if err := repo.CreateRefreshToken(ctx, "token-a", refresh); err != nil {
+		t.Fatalf("CreateRefreshToken: %v", err)
+	}
+	if _, err := repo.ConsumeRefreshToken(ctx, "token-a"); err != nil {
+		t.Fatalf("ConsumeRefreshToken: %v", err)
+	}
+	if err := repo.CreateRefreshToken(ctx, "token-b", refresh); err != nil {
+		t.Fatalf("CreateRefreshToken: %v", err)
+	}
+
+	if _, err := repo.ConsumeRefreshToken(ctx, "token-a"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("old token: got %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	if _, err := repo.ConsumeRefreshToken(ctx, "token-b"); err != nil {
+		t.Errorf("rotated token: got %v, want none", err)
+	}
+}
+
+func TestDeleteRefreshFamily(t *testing.T) {
+	ctx := context.Background()
+	repo, server := newTestSessionRepository(t)
+	refresh := entity.RefreshTokenEntity{UserID: 7, FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour)}
+
+	if err := repo.CreateRefreshToken(ctx, "token-a", refresh); err != nil {
+		t.Fatalf("CreateRefreshToken: %v", err)
+	}
+
+	if err := repo.DeleteRefreshFamily(ctx, refresh.UserID, refresh.FamilyID); err != nil {
+		t.Fatalf("DeleteRefreshFamily: %v", err)
+	}
+
+	if _, err := repo.ConsumeRefreshToken(ctx, "token-a"); err == nil || err.Error() != "401" {
+		t.Errorf("got %v, want 401", err)
+	}
+
+	for _, key := range []string{refreshFamilyKey(refresh.FamilyID), refreshTokenKey(hashRefreshToken("token-a"))} {
+		if server.Exists(key) {
+			t.Errorf("%s still exists", key)
+		}
+	}
+
+	if members, _ := server.Members(userRefreshFamiliesKey(refresh.UserID)); len(members) != 0 {
+		t.Errorf("family index still has %v", members)
+	}
+}
+
+func TestDeleteRefreshFamilyWithoutToken(t *testing.T) {
+	repo, _ := newTestSessionRepository(t)
+
+	if err := repo.DeleteRefreshFamily(context.Background(), 7, "missing"); err != nil {
+		t.Errorf("got %v, want none", err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"time"
 	"user-service/internal/core/domain/entity"
 	"user-service/internal/core/domain/model"
 
@@ -15,7 +14,7 @@ import (
 
 type UserRepositoryInterface interface {
 	GetUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error)
-	CreateUserAccount(ctx context.Context, req entity.UserEntity) error
+	CreateUserAccount(ctx context.Context, req entity.UserEntity) (int64, error)
 	UpdateUserVerified(ctx context.Context, userID int64) (*entity.UserEntity, error)
 	UpdatePasswordByID(ctx context.Context, req entity.UserEntity) error
 	GetUserByID(ctx context.Context, userID int64) (*entity.UserEntity, error)
@@ -24,7 +23,8 @@ type UserRepositoryInterface interface {
 	// Modul Customers Admin
 	GetCustomerAll(ctx context.Context, query entity.QueryStringCustomer) ([]entity.UserEntity, int64, int64, error)
 	GetCustomerByID(ctx context.Context, customerID int64) (*entity.UserEntity, error)
-	CreateCustomer(ctx context.Context, req entity.UserEntity) error
+	GetCustomersByIDs(ctx context.Context, customerIDs []int64) ([]entity.UserEntity, error)
+	CreateCustomer(ctx context.Context, req entity.UserEntity) (int64, error)
 	UpdateCustomer(ctx context.Context, req entity.UserEntity) error
 	DeleteCustomer(ctx context.Context, customerID int64) error
 }
@@ -55,11 +55,17 @@ func (u *userRepository) DeleteCustomer(ctx context.Context, customerID int64) e
 }
 
 // UpdateCustomer implements UserRepositoryInterface.
+// It returns "422" when the role does not exist.
 func (u *userRepository) UpdateCustomer(ctx context.Context, req entity.UserEntity) error {
 	modelRole := model.Role{}
 
 	if err := u.db.Where("id =?", req.RoleID).First(&modelRole).Error; err != nil {
-		log.Fatalf("[UserRepository-1] UpdateCustomer: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errors.New("422")
+			log.Infof("[UserRepository-1] UpdateCustomer: role %d not found", req.RoleID)
+			return err
+		}
+		log.Errorf("[UserRepository-1] UpdateCustomer: %v", err)
 		return err
 	}
 
@@ -93,8 +99,11 @@ func (u *userRepository) UpdateCustomer(ctx context.Context, req entity.UserEnti
 		modelUser.Lat = req.Lat
 	}
 
+	// A password set by an admin is only temporary: the customer has to
+	// choose a new one through the emailed link before signing in again.
 	if req.Password != "" {
 		modelUser.Password = req.Password
+		modelUser.PasswordResetRequired = true
 	}
 
 	if err := u.db.Save(&modelUser).Error; err != nil {
@@ -106,12 +115,18 @@ func (u *userRepository) UpdateCustomer(ctx context.Context, req entity.UserEnti
 }
 
 // CreateCustomer implements UserRepositoryInterface.
-func (u *userRepository) CreateCustomer(ctx context.Context, req entity.UserEntity) error {
+// It returns the ID of the new customer, or "422" when the role does not exist.
+func (u *userRepository) CreateCustomer(ctx context.Context, req entity.UserEntity) (int64, error) {
 	modelRole := model.Role{}
 
 	if err := u.db.Where("id =?", req.RoleID).First(&modelRole).Error; err != nil {
-		log.Fatalf("[UserRepository-1] CreateCustomer: %v", err)
-		return err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errors.New("422")
+			log.Infof("[UserRepository-1] CreateCustomer: role %d not found", req.RoleID)
+			return 0, err
+		}
+		log.Errorf("[UserRepository-1] CreateCustomer: %v", err)
+		return 0, err
 	}
 
 	modelUser := model.User{
@@ -129,10 +144,10 @@ func (u *userRepository) CreateCustomer(ctx context.Context, req entity.UserEnti
 
 	if err := u.db.Create(&modelUser).Error; err != nil {
 		log.Errorf("[UserRepository-2] CreateCustomer: %v", err)
-		return err
+		return 0, err
 	}
 
-	return nil
+	return modelUser.ID, nil
 }
 
 // GetCustomerByID implements UserRepositoryInterface.
@@ -167,6 +182,39 @@ func (u *userRepository) GetCustomerByID(ctx context.Context, customerID int64)
 	}, nil
 }
 
+// GetCustomersByIDs implements UserRepositoryInterface.
+// IDs that do not exist are skipped, so the result may be shorter than the request.
+func (u *userRepository) GetCustomersByIDs(ctx context.Context, customerIDs []int64) ([]entity.UserEntity, error) {
+	modelUsers := []model.User{}
+
+	if err := u.db.WithContext(ctx).Where("id IN ?", customerIDs).Preload("Roles").Find(&modelUsers).Error; err != nil {
+		log.Errorf("[UserRepository-1] GetCustomersByIDs: %v", err)
+		return nil, err
+	}
+
+	entities := []entity.UserEntity{}
+	for _, val := range modelUsers {
+		roleID := 0
+		for _, role := range val.Roles {
+			roleID = int(role.ID)
+		}
+
+		entities = append(entities, entity.UserEntity{
+			ID:      val.ID,
+			Name:    val.Name,
+			Email:   val.Email,
+			RoleID:  int64(roleID),
+			Address: val.Address,
+			Lat:     val.Lat,
+			Lng:     val.Lng,
+			Phone:   val.Phone,
+			Photo:   val.Photo,
+		})
+	}
+
+	return entities, nil
+}
+
 // GetCustomerAll implements UserRepositoryInterface.
 func (u *userRepository) GetCustomerAll(ctx context.Context, query entity.QueryStringCustomer) ([]entity.UserEntity, int64, int64, error) {
 	modelUsers := []model.User{}
@@ -269,6 +317,9 @@ func (u *userRepository) GetUserByID(ctx context.Context, userID int64) (*entity
 		Address:  modelUser.Address,
 		Phone:    modelUser.Phone,
 		Photo:    modelUser.Photo,
+
+		IsVerified:            modelUser.IsVerified,
+		PasswordResetRequired: modelUser.PasswordResetRequired,
 	}, nil
 }
 
@@ -287,6 +338,7 @@ func (u *userRepository) UpdatePasswordByID(ctx context.Context, req entity.User
 	}
 
 	modelUser.Password = req.Password
+	modelUser.PasswordResetRequired = false
 	if err := u.db.Save(&modelUser).Error; err != nil {
 		log.Errorf("[UserRepository-3] UpdatePasswordByID: %v", err)
 		return err
@@ -330,12 +382,13 @@ func (u *userRepository) UpdateUserVerified(ctx context.Context, userID int64) (
 }
 
 // CreateUserAccount implements UserRepositoryInterface.
-func (u *userRepository) CreateUserAccount(ctx context.Context, req entity.UserEntity) error {
+// It returns the ID of the new, still unverified user.
+func (u *userRepository) CreateUserAccount(ctx context.Context, req entity.UserEntity) (int64, error) {
 	modelRole := model.Role{}
 	err := u.db.Where("name = ?", "Customer").First(&modelRole).Error
 	if err != nil {
 		log.Errorf("[UserRepository-1] CreateUserAccount: %v", err)
-		return err
+		return 0, err
 	}
 
 	modelUser := model.User{
@@ -347,23 +400,10 @@ func (u *userRepository) CreateUserAccount(ctx context.Context, req entity.UserE
 
 	if err := u.db.Create(&modelUser).Error; err != nil {
 		log.Errorf("[UserRepository-2] CreateUserAccount: %v", err)
-		return err
-	}
-
-	currentTime := time.Now()
-	modelVerify := model.VerificationToken{
-		UserID:    modelUser.ID,
-		Token:     req.Token,
-		TokenType: "email_verification",
-		ExpiresAt: currentTime.Add(time.Hour * 1),
-	}
-
-	if err := u.db.Create(&modelVerify).Error; err != nil {
-		log.Errorf("[UserRepository-3] CreateUserAccount: %v", err)
-		return err
+		return 0, err
 	}
 
-	return nil
+	return modelUser.ID, nil
 }
 
 func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error) {
@@ -392,6 +432,8 @@ func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*ent
 		Phone:      modelUser.Phone,
 		Photo:      modelUser.Photo,
 		IsVerified: modelUser.IsVerified,
+
+		PasswordResetRequired: modelUser.PasswordResetRequired,
 	}, nil
 }
 
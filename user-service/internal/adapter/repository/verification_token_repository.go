@@ -9,11 +9,14 @@ import (
 
 	"github.com/labstack/gommon/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type VerificationTokenRepositoryInterface interface {
 	CreateVerificationToken(ctx context.Context, req entity.VerificationTokenEntity) error
 	GetDataByToken(ctx context.Context, token string) (*entity.VerificationTokenEntity, error)
+	ConsumeToken(ctx context.Context, token string, tokenTypes []string) (*entity.VerificationTokenEntity, error)
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
 }
 
 type verificationTokenRepository struct {
@@ -35,7 +38,7 @@ func (v *verificationTokenRepository) GetDataByToken(ctx context.Context, token
 	}
 
 	currentTime := time.Now()
-	if currentTime.After(modelToken.ExpiresAt) {
+	if currentTime.After(modelToken.ExpiresAt) || modelToken.ConsumedAt != nil {
 		err := errors.New("401")
 		log.Errorf("[VerificationTokenRepository-3] GetDataByToken: %v", err)
 		return nil, err
@@ -50,12 +53,69 @@ func (v *verificationTokenRepository) GetDataByToken(ctx context.Context, token
 	}, nil
 }
 
+// ConsumeToken implements VerificationTokenRepositoryInterface.
+// The token is marked consumed in the same UPDATE that checks it, so two
+// requests racing with one token cannot both use it. It returns "404" when the
+// token does not exist and "401" when it is expired, already used or of a type
+// not in tokenTypes.
+func (v *verificationTokenRepository) ConsumeToken(ctx context.Context, token string, tokenTypes []string) (*entity.VerificationTokenEntity, error) {
+	modelToken := model.VerificationToken{}
+	now := time.Now()
+
+	result := v.db.WithContext(ctx).Model(&modelToken).Clauses(clause.Returning{}).
+		Where("token = ? AND token_type IN ? AND consumed_at IS NULL AND expires_at > ?", token, tokenTypes, now).
+		Update("consumed_at", now)
+	if result.Error != nil {
+		log.Errorf("[VerificationTokenRepository-1] ConsumeToken: %v", result.Error)
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := v.db.WithContext(ctx).Model(&model.VerificationToken{}).Where("token = ?", token).Count(&count).Error; err != nil {
+			log.Errorf("[VerificationTokenRepository-2] ConsumeToken: %v", err)
+			return nil, err
+		}
+
+		err := errors.New("401")
+		if count == 0 {
+			err = errors.New("404")
+		}
+		log.Errorf("[VerificationTokenRepository-3] ConsumeToken: %v", err)
+		return nil, err
+	}
+
+	return &entity.VerificationTokenEntity{
+		ID:        modelToken.ID,
+		UserID:    modelToken.UserID,
+		Token:     token,
+		TokenType: modelToken.TokenType,
+		ExpiresAt: modelToken.ExpiresAt,
+	}, nil
+}
+
+// DeleteExpiredTokens implements VerificationTokenRepositoryInterface.
+// It removes tokens that have expired or have already been used and returns
+// how many were deleted.
+func (v *verificationTokenRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	result := v.db.WithContext(ctx).
+		Where("expires_at <= ? OR consumed_at IS NOT NULL", time.Now()).
+		Delete(&model.VerificationToken{})
+	if result.Error != nil {
+		log.Errorf("[VerificationTokenRepository-1] DeleteExpiredTokens: %v", result.Error)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
 // CreateVerificationToken implements VerificationTokenRepositoryInterface.
 func (v *verificationTokenRepository) CreateVerificationToken(ctx context.Context, req entity.VerificationTokenEntity) error {
 	modelVerificationToken := model.VerificationToken{
 		UserID:    req.UserID,
 		Token:     req.Token,
 		TokenType: req.TokenType,
+		ExpiresAt: req.ExpiresAt,
 	}
 
 	if err := v.db.Create(&modelVerificationToken).Error; err != nil {
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+	"user-service/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestVerificationTokenRepository(t *testing.T) (*verificationTokenRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger:                 logger.Default.LogMode(logger.Silent),
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	return &verificationTokenRepository{db: db}, mock
+}
+
+// recentTime matches the time ConsumeToken compares expires_at against, so a
+// test fails if expired tokens stop being rejected.
+type recentTime struct{}
+
+func (recentTime) Match(v driver.Value) bool {
+	at, ok := v.(time.Time)
+	return ok && time.Since(at) >= 0 && time.Since(at) < time.Minute
+}
+
+func TestConsumeToken(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	columns := []string{"id", "user_id", "token", "token_type", "expires_at"}
+
+	tests := []struct {
+		name       string
+		tokenTypes []string
+		// consumed is the row the UPDATE returns, nil when the WHERE clause
+		// matches nothing because the token is of another type, expired or
+		// already used.
+		consumed *sqlmock.Rows
+		// stored is how many rows hold the token at all.
+		stored  int64
+		wantErr string
+	}{
+		{
+			name:       "valid token",
+			tokenTypes: []string{utils.NOTIF_EMAIL_VERIFICATION},
+			consumed:   sqlmock.NewRows(columns).AddRow(1, 7, "token-a", utils.NOTIF_EMAIL_VERIFICATION, expiresAt),
+		},
+		{
+			name:       "wrong type, expired or used token",
+			tokenTypes: []string{utils.NOTIF_EMAIL_FORGOT_PASSWORD},
+			stored:     1,
+			wantErr:    "401",
+		},
+		{
+			name:       "unknown token",
+			tokenTypes: []string{utils.NOTIF_EMAIL_VERIFICATION},
+			wantErr:    "404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newTestVerificationTokenRepository(t)
+
+			consumed := tt.consumed
+			if consumed == nil {
+				consumed = sqlmock.NewRows(columns)
+			}
+			mock.ExpectQuery(`UPDATE "verification_tokens" SET "consumed_at"=.* WHERE token = \$3 AND token_type IN \(\$4\) AND consumed_at IS NULL AND expires_at > \$5 RETURNING \*`).
+				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "token-a", tt.tokenTypes[0], recentTime{}).
+				WillReturnRows(consumed)
+			if tt.consumed == nil {
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "verification_tokens" WHERE token = \$1`).
+					WithArgs("token-a").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.stored))
+			}
+
+			got, err := repo.ConsumeToken(context.Background(), "token-a", tt.tokenTypes)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("got error %v, want none", err)
+				}
+				if got.UserID != 7 || got.TokenType != utils.NOTIF_EMAIL_VERIFICATION {
+					t.Errorf("got %+v, want user 7 with a %s token", got, utils.NOTIF_EMAIL_VERIFICATION)
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("got error %v, want %s", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 	"user-service/config"
 	"user-service/internal/adapter/handler"
+	"user-service/internal/adapter/message"
 	"user-service/internal/adapter/repository"
 	"user-service/internal/adapter/storage"
 	"user-service/internal/core/service"
@@ -32,9 +33,15 @@ func RunServer() {
 	userRepo := repository.NewUserRepository(db.DB)
 	tokenRepo := repository.NewVerificationTokenRepository(db.DB)
 	roleRepo := repository.NewRoleRepository(db.DB)
+	sessionRepo := repository.NewSessionRepository(cfg.NewRedisClient())
+
+	rabbitPublisher := message.NewRabbitMQPublisher(cfg)
+	defer rabbitPublisher.Close()
+
+	messageRabbit := message.NewPublishRabbitMQ(cfg, rabbitPublisher)
 
 	jwtService := service.NewJwtService(cfg)
-	userService := service.NewUserService(userRepo, cfg, jwtService, tokenRepo)
+	userService := service.NewUserService(userRepo, cfg, jwtService, tokenRepo, sessionRepo, messageRabbit)
 	roleService := service.NewRoleService(roleRepo)
 
 	e := echo.New()
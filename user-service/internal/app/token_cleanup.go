@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"user-service/config"
+	"user-service/internal/adapter/repository"
+)
+
+const defaultTokenCleanupInterval = 3600
+
+// RunTokenCleanup deletes expired and used verification tokens right away and
+// then every TOKEN_CLEANUP_INTERVAL seconds until the process is stopped.
+func RunTokenCleanup() {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatalf("[RunTokenCleanup-1] %v", err)
+		return
+	}
+
+	tokenRepo := repository.NewVerificationTokenRepository(db.DB)
+
+	interval := cfg.App.TokenCleanupInterval
+	if interval <= 0 {
+		interval = defaultTokenCleanupInterval
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := tokenRepo.DeleteExpiredTokens(ctx)
+		if err != nil {
+			log.Printf("[RunTokenCleanup-2] %v", err)
+		} else {
+			log.Printf("[RunTokenCleanup-3] Deleted %d expired or used tokens", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Print("[RunTokenCleanup-4] Stopping token cleanup")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -8,4 +8,6 @@ type JwtUserData struct {
 	Token     string `json:"token"`
 	UserID    int64  `json:"user_id"`
 	RoleName  string `json:"role_name"`
+
+	RefreshFamily string `json:"refresh_family,omitempty"`
 }
@@ -0,0 +1,7 @@
+package entity
+
+// NotificationEntity is the body published to the NOTIF_EMAIL_* queues.
+type NotificationEntity struct {
+	Email   string `json:"email"`
+	Message string `json:"message"`
+}
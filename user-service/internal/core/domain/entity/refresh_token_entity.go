@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// RefreshTokenEntity is what is stored server-side for a refresh token. Every
+// token rotated from the same sign in shares the FamilyID.
+type RefreshTokenEntity struct {
+	UserID    int64     `json:"user_id"`
+	FamilyID  string    `json:"family_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,19 +1,21 @@
 package entity
 
 type UserEntity struct {
-	ID         int64
-	Name       string
-	Email      string
-	Password   string
-	RoleName   string
-	RoleID     int64
-	Address    string
-	Lat        string
-	Lng        string
-	Phone      string
-	Photo      string
-	IsVerified bool
-	Token      string
+	ID                    int64
+	Name                  string
+	Email                 string
+	Password              string
+	RoleName              string
+	RoleID                int64
+	Address               string
+	Lat                   string
+	Lng                   string
+	Phone                 string
+	Photo                 string
+	IsVerified            bool
+	PasswordResetRequired bool
+	Token                 string
+	RefreshToken          string
 }
 
 type QueryStringCustomer struct {
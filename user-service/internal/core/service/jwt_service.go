@@ -5,23 +5,34 @@ import (
 	"user-service/config"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+const defaultAccessTokenTTL = 15 * 60
+
 type JwtServiceInterface interface {
 	GenerateToken(userID int64) (string, error)
 	ValidateToken(token string) (*jwt.Token, error)
+	AccessTokenTTL() time.Duration
 }
 
 type jwtService struct {
 	secretKey string
 	issuer    string
+	ttl       time.Duration
 }
 
+// GenerateToken issues a short-lived access token. The jti keeps two tokens
+// issued for the same user in the same second apart, as each one is the key of
+// its own session.
 func (j *jwtService) GenerateToken(userID int64) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"user_id": userID,
 		"iss":     j.issuer,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"iat":     now.Unix(),
+		"exp":     now.Add(j.ttl).Unix(),
+		"jti":     uuid.New().String(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -38,9 +49,19 @@ func (j *jwtService) ValidateToken(encodetoken string) (*jwt.Token, error) {
 	})
 }
 
+func (j *jwtService) AccessTokenTTL() time.Duration {
+	return j.ttl
+}
+
 func NewJwtService(cfg *config.Config) JwtServiceInterface {
+	ttl := cfg.App.AccessTokenTTL
+	if ttl <= 0 {
+		ttl = defaultAccessTokenTTL
+	}
+
 	return &jwtService{
 		secretKey: cfg.App.JwtSecretKey,
 		issuer:    cfg.App.JwtIssuer,
+		ttl:       time.Duration(ttl) * time.Second,
 	}
 }
@@ -2,7 +2,8 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"time"
@@ -17,6 +18,14 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
+const (
+	defaultTokenTTLEmailVerification = 3600
+	defaultTokenTTLResetPassword     = 3600
+	defaultTokenTTLInvitation        = 72 * 3600
+
+	defaultRefreshTokenTTL = 7 * 24 * 3600
+)
+
 type UserServiceInterface interface {
 	SignIn(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, string, error)
 	CreateUserAccount(ctx context.Context, req entity.UserEntity) error
@@ -25,35 +34,57 @@ type UserServiceInterface interface {
 	UpdatePassword(ctx context.Context, req entity.UserEntity) error
 	GetProfileUser(ctx context.Context, userID int64) (*entity.UserEntity, error)
 	UpdateDataUser(ctx context.Context, req entity.UserEntity) error
+	RefreshToken(ctx context.Context, refreshToken string) (*entity.UserEntity, error)
+	Logout(ctx context.Context, session entity.JwtUserData) error
+	LogoutAll(ctx context.Context, userID int64) error
 
 	// Modul Customers Admin
 	GetCustomerAll(ctx context.Context, query entity.QueryStringCustomer) ([]entity.UserEntity, int64, int64, error)
 	GetCustomerByID(ctx context.Context, customerID int64) (*entity.UserEntity, error)
+	GetCustomersByIDs(ctx context.Context, customerIDs []int64) ([]entity.UserEntity, error)
 	CreateCustomer(ctx context.Context, req entity.UserEntity) error
 	UpdateCustomer(ctx context.Context, req entity.UserEntity) error
 	DeleteCustomer(ctx context.Context, customerID int64) error
 }
 
 type userService struct {
-	repo       repository.UserRepositoryInterface
-	cfg        *config.Config
-	jwtService JwtServiceInterface
-	repoToken  repository.VerificationTokenRepositoryInterface
+	repo        repository.UserRepositoryInterface
+	cfg         *config.Config
+	jwtService  JwtServiceInterface
+	repoToken   repository.VerificationTokenRepositoryInterface
+	repoSession repository.SessionRepositoryInterface
+
+	publisherRabbitMQ message.PublishRabbitMQInterface
 }
 
 // DeleteCustomer implements UserServiceInterface.
 func (u *userService) DeleteCustomer(ctx context.Context, customerID int64) error {
-	return u.repo.DeleteCustomer(ctx, customerID)
+	if err := u.repo.DeleteCustomer(ctx, customerID); err != nil {
+		return err
+	}
+
+	if _, err := u.repoSession.DeleteUserSessions(ctx, customerID); err != nil {
+		log.Errorf("[UserService-1] DeleteCustomer: %v", err)
+		return err
+	}
+
+	if err := u.publisherRabbitMQ.PublishCustomerChanged(customerID); err != nil {
+		log.Errorf("[UserService-2] DeleteCustomer: %v", err)
+	}
+
+	return nil
 }
 
 // UpdateCustomer implements UserServiceInterface.
+// A password set here is never sent to the customer. Their sessions end and
+// they are emailed a reset password link to choose a new one instead, valid
+// as long as a forgotten password link.
 func (u *userService) UpdateCustomer(ctx context.Context, req entity.UserEntity) error {
-	passwordNoencrypt := ""
-	if req.Password != "" {
-		passwordNoencrypt = req.Password
+	passwordChanged := req.Password != ""
+	if passwordChanged {
 		password, err := conv.HashPassword(req.Password)
 		if err != nil {
-			log.Fatalf("[UserService-1] UpdateCustomer: %v", err)
+			log.Errorf("[UserService-1] UpdateCustomer: %v", err)
 			return err
 		}
 
@@ -62,17 +93,25 @@ func (u *userService) UpdateCustomer(ctx context.Context, req entity.UserEntity)
 
 	err := u.repo.UpdateCustomer(ctx, req)
 	if err != nil {
-		log.Fatalf("[UserService-2] UpdateCustomer: %v", err)
+		log.Errorf("[UserService-2] UpdateCustomer: %v", err)
 		return err
 	}
 
-	if passwordNoencrypt != "" {
-		messageparam := fmt.Sprintf("You're account has been updated. Please login use: \n Email: %s\nPassword: %s", req.Email, passwordNoencrypt)
-		err = message.PublishMessage(req.Email,
-			messageparam,
-			utils.NOTIF_EMAIL_UPDATE_CUSTOMER)
+	if err = u.publisherRabbitMQ.PublishCustomerChanged(req.ID); err != nil {
+		log.Errorf("[UserService-3] UpdateCustomer: %v", err)
+	}
+
+	if passwordChanged {
+		// Sessions opened with the old password end now.
+		if _, err = u.repoSession.DeleteUserSessions(ctx, req.ID); err != nil {
+			log.Errorf("[UserService-4] UpdateCustomer: %v", err)
+			return err
+		}
+
+		intro := "Your password has been reset by an administrator. Please choose a new password using the link below:"
+		err = u.sendPasswordLink(ctx, req.ID, req.Email, utils.NOTIF_EMAIL_FORGOT_PASSWORD, utils.NOTIF_EMAIL_UPDATE_CUSTOMER, intro)
 		if err != nil {
-			log.Errorf("[UserService-3] UpdateCustomer: %v", err)
+			log.Errorf("[UserService-5] UpdateCustomer: %v", err)
 			return err
 		}
 	}
@@ -81,23 +120,24 @@ func (u *userService) UpdateCustomer(ctx context.Context, req entity.UserEntity)
 }
 
 // CreateCustomer implements UserServiceInterface.
+// The customer gets an invitation link to choose their own password, so the
+// account starts with a random password nobody knows.
 func (u *userService) CreateCustomer(ctx context.Context, req entity.UserEntity) error {
-	passwordNoEncrypt := req.Password
-	password, err := conv.HashPassword(passwordNoEncrypt)
+	password, err := conv.HashPassword(uuid.New().String())
 	if err != nil {
-		log.Fatalf("[UserService-1] CreateCustomer: %v", err)
+		log.Errorf("[UserService-1] CreateCustomer: %v", err)
 		return err
 	}
 
 	req.Password = password
-	err = u.repo.CreateCustomer(ctx, req)
+	customerID, err := u.repo.CreateCustomer(ctx, req)
 	if err != nil {
-		log.Fatalf("[UserService-2] CreateCustomer: %v", err)
+		log.Errorf("[UserService-2] CreateCustomer: %v", err)
 		return err
 	}
 
-	messageparam := fmt.Sprintf("You have been registered in Sayur Project. Please login use: \n Email: %s\nPassword: %s", req.Email, passwordNoEncrypt)
-	err = message.PublishMessage(req.Email, messageparam, utils.NOTIF_EMAIL_CREATE_CUSTOMER)
+	intro := "You have been registered in Sayur Project. Please set your password using the link below:"
+	err = u.sendPasswordLink(ctx, customerID, req.Email, utils.NOTIF_EMAIL_CREATE_CUSTOMER, utils.NOTIF_EMAIL_CREATE_CUSTOMER, intro)
 	if err != nil {
 		log.Errorf("[UserService-3] CreateCustomer: %v", err)
 		return err
@@ -106,11 +146,69 @@ func (u *userService) CreateCustomer(ctx context.Context, req entity.UserEntity)
 	return nil
 }
 
+// sendPasswordLink stores a one-time token of tokenType for the user and emails
+// a link to set a new password with it as a notifType notification.
+func (u *userService) sendPasswordLink(ctx context.Context, userID int64, email, tokenType, notifType, intro string) error {
+	token, err := u.createToken(ctx, userID, tokenType)
+	if err != nil {
+		return err
+	}
+
+	urlPassword := fmt.Sprintf("%s/forgot-password?token=%s", u.cfg.App.UrlForgotPassword, token)
+	return u.publisherRabbitMQ.PublishMessage(email, fmt.Sprintf("%s\n%s", intro, urlPassword), notifType)
+}
+
+// createToken stores a new one-time token of tokenType for the user, valid for
+// the TTL configured for that type.
+func (u *userService) createToken(ctx context.Context, userID int64, tokenType string) (string, error) {
+	token := uuid.New().String()
+	reqEntity := entity.VerificationTokenEntity{
+		UserID:    userID,
+		Token:     token,
+		TokenType: tokenType,
+		ExpiresAt: time.Now().Add(u.tokenTTL(tokenType)),
+	}
+
+	if err := u.repoToken.CreateVerificationToken(ctx, reqEntity); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (u *userService) tokenTTL(tokenType string) time.Duration {
+	ttl := 0
+	switch tokenType {
+	case utils.NOTIF_EMAIL_VERIFICATION:
+		ttl = u.cfg.App.TokenTTLEmailVerification
+		if ttl <= 0 {
+			ttl = defaultTokenTTLEmailVerification
+		}
+	case utils.NOTIF_EMAIL_FORGOT_PASSWORD:
+		ttl = u.cfg.App.TokenTTLResetPassword
+		if ttl <= 0 {
+			ttl = defaultTokenTTLResetPassword
+		}
+	case utils.NOTIF_EMAIL_CREATE_CUSTOMER:
+		ttl = u.cfg.App.TokenTTLInvitation
+		if ttl <= 0 {
+			ttl = defaultTokenTTLInvitation
+		}
+	}
+
+	return time.Duration(ttl) * time.Second
+}
+
 // GetCustomerByID implements UserServiceInterface.
 func (u *userService) GetCustomerByID(ctx context.Context, customerID int64) (*entity.UserEntity, error) {
 	return u.repo.GetCustomerByID(ctx, customerID)
 }
 
+// GetCustomersByIDs implements UserServiceInterface.
+func (u *userService) GetCustomersByIDs(ctx context.Context, customerIDs []int64) ([]entity.UserEntity, error) {
+	return u.repo.GetCustomersByIDs(ctx, customerIDs)
+}
+
 // GetCustomerAll implements UserServiceInterface.
 func (u *userService) GetCustomerAll(ctx context.Context, query entity.QueryStringCustomer) ([]entity.UserEntity, int64, int64, error) {
 	return u.repo.GetCustomerAll(ctx, query)
@@ -118,7 +216,93 @@ func (u *userService) GetCustomerAll(ctx context.Context, query entity.QueryStri
 
 // UpdateDataUser implements UserServiceInterface.
 func (u *userService) UpdateDataUser(ctx context.Context, req entity.UserEntity) error {
-	return u.repo.UpdateDataUser(ctx, req)
+	if err := u.repo.UpdateDataUser(ctx, req); err != nil {
+		return err
+	}
+
+	if err := u.publisherRabbitMQ.PublishCustomerChanged(req.ID); err != nil {
+		log.Errorf("[UserService-1] UpdateDataUser: %v", err)
+	}
+
+	return nil
+}
+
+// RefreshToken implements UserServiceInterface.
+// The refresh token is rotated: it is spent and a new one from the same family
+// is returned with the new access token. A token presented after it was spent
+// has been copied, so every session of the user is revoked. The user is loaded
+// again on every rotation, so a deleted or unverified user and one who has to
+// reset the password get no new access token.
+func (u *userService) RefreshToken(ctx context.Context, refreshToken string) (*entity.UserEntity, error) {
+	refresh, err := u.repoSession.ConsumeRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			log.Warnf("[UserService-1] RefreshToken: reused refresh token for user %d, revoking all sessions", refresh.UserID)
+			if _, err = u.repoSession.DeleteUserSessions(ctx, refresh.UserID); err != nil {
+				log.Errorf("[UserService-2] RefreshToken: %v", err)
+				return nil, err
+			}
+			return nil, errors.New("401")
+		}
+
+		log.Errorf("[UserService-3] RefreshToken: %v", err)
+		return nil, err
+	}
+
+	user, err := u.repo.GetUserByID(ctx, refresh.UserID)
+	if err != nil {
+		log.Errorf("[UserService-4] RefreshToken: %v", err)
+		if err.Error() == "404" {
+			return nil, errors.New("401")
+		}
+		return nil, err
+	}
+
+	if user.PasswordResetRequired {
+		log.Infof("[UserService-5] RefreshToken: user %d has to reset the password, revoking the refresh token family", user.ID)
+		if err = u.repoSession.DeleteRefreshFamily(ctx, user.ID, refresh.FamilyID); err != nil {
+			log.Errorf("[UserService-6] RefreshToken: %v", err)
+			return nil, err
+		}
+		return nil, errors.New("401")
+	}
+
+	if err = u.startSession(ctx, user, refresh.FamilyID); err != nil {
+		log.Errorf("[UserService-7] RefreshToken: %v", err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Logout implements UserServiceInterface.
+// It ends the session of the access token and the refresh tokens issued with it.
+func (u *userService) Logout(ctx context.Context, session entity.JwtUserData) error {
+	if err := u.repoSession.DeleteSession(ctx, session.UserID, session.Token); err != nil {
+		log.Errorf("[UserService-1] Logout: %v", err)
+		return err
+	}
+
+	if session.RefreshFamily == "" {
+		return nil
+	}
+
+	if err := u.repoSession.DeleteRefreshFamily(ctx, session.UserID, session.RefreshFamily); err != nil {
+		log.Errorf("[UserService-2] Logout: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// LogoutAll implements UserServiceInterface.
+func (u *userService) LogoutAll(ctx context.Context, userID int64) error {
+	if _, err := u.repoSession.DeleteUserSessions(ctx, userID); err != nil {
+		log.Errorf("[UserService-1] LogoutAll: %v", err)
+		return err
+	}
+
+	return nil
 }
 
 // GetProfileUser implements UserServiceInterface.
@@ -127,29 +311,32 @@ func (u *userService) GetProfileUser(ctx context.Context, userID int64) (*entity
 }
 
 // UpdatePassword implements UserServiceInterface.
+// Every session and refresh token of the user is revoked, so whoever knew the
+// old password is signed out too.
 func (u *userService) UpdatePassword(ctx context.Context, req entity.UserEntity) error {
-	token, err := u.repoToken.GetDataByToken(ctx, req.Token)
+	password, err := conv.HashPassword(req.Password)
 	if err != nil {
 		log.Errorf("[UserService-1] UpdatePassword: %v", err)
 		return err
 	}
 
-	if token.TokenType != "reset_password" {
-		err = errors.New("401")
+	// Invitation links of admin-created customers set the first password.
+	token, err := u.repoToken.ConsumeToken(ctx, req.Token, []string{utils.NOTIF_EMAIL_FORGOT_PASSWORD, utils.NOTIF_EMAIL_CREATE_CUSTOMER})
+	if err != nil {
 		log.Errorf("[UserService-2] UpdatePassword: %v", err)
 		return err
 	}
 
-	password, err := conv.HashPassword(req.Password)
-	if err != nil {
-		log.Errorf("[UserService-3] UpdatePassword: %v", err)
-		return err
-	}
 	req.Password = password
 	req.ID = token.UserID
 
 	err = u.repo.UpdatePasswordByID(ctx, req)
 	if err != nil {
+		log.Errorf("[UserService-3] UpdatePassword: %v", err)
+		return err
+	}
+
+	if _, err = u.repoSession.DeleteUserSessions(ctx, token.UserID); err != nil {
 		log.Errorf("[UserService-4] UpdatePassword: %v", err)
 		return err
 	}
@@ -159,7 +346,7 @@ func (u *userService) UpdatePassword(ctx context.Context, req entity.UserEntity)
 
 // VerifyToken implements UserServiceInterface.
 func (u *userService) VerifyToken(ctx context.Context, token string) (*entity.UserEntity, error) {
-	verifyToken, err := u.repoToken.GetDataByToken(ctx, token)
+	verifyToken, err := u.repoToken.ConsumeToken(ctx, token, []string{utils.NOTIF_EMAIL_VERIFICATION})
 	if err != nil {
 		log.Errorf("[UserService-1] VerifyToken: %v", err)
 		return nil, err
@@ -171,37 +358,11 @@ func (u *userService) VerifyToken(ctx context.Context, token string) (*entity.Us
 		return nil, err
 	}
 
-	accessToken, err := u.jwtService.GenerateToken(user.ID)
-	if err != nil {
+	if err = u.startSession(ctx, user, ""); err != nil {
 		log.Errorf("[UserService-3] VerifyToken: %v", err)
 		return nil, err
 	}
 
-	sessionData := map[string]interface{}{
-		"user_id":    user.ID,
-		"name":       user.Name,
-		"email":      user.Email,
-		"logged_in":  true,
-		"created_at": time.Now().String(),
-		"token":      token,
-		"role_name":  user.RoleName,
-	}
-
-	jsonData, err := json.Marshal(sessionData)
-	if err != nil {
-		fmt.Println("Error encoding JSON:", err)
-		return nil, err
-	}
-
-	redisConn := config.NewConfig().NewRedisClient()
-	err = redisConn.Set(ctx, token, jsonData, time.Hour*23).Err()
-	if err != nil {
-		log.Errorf("[UserService-4] VerifyToken: %v", err)
-		return nil, err
-	}
-
-	user.Token = accessToken
-
 	return user, nil
 }
 
@@ -213,14 +374,7 @@ func (u *userService) ForgotPassword(ctx context.Context, req entity.UserEntity)
 		return err
 	}
 
-	token := uuid.New().String()
-	reqEntity := entity.VerificationTokenEntity{
-		UserID:    user.ID,
-		Token:     token,
-		TokenType: utils.NOTIF_EMAIL_FORGOT_PASSWORD,
-	}
-
-	err = u.repoToken.CreateVerificationToken(ctx, reqEntity)
+	token, err := u.createToken(ctx, user.ID, utils.NOTIF_EMAIL_FORGOT_PASSWORD)
 	if err != nil {
 		log.Errorf("[UserService-2] ForgotPassword: %v", err)
 		return err
@@ -228,7 +382,7 @@ func (u *userService) ForgotPassword(ctx context.Context, req entity.UserEntity)
 
 	urlForgot := fmt.Sprintf("%s/forgot-password?token=%s", u.cfg.App.UrlForgotPassword, token)
 	messageparam := fmt.Sprintf("Please click link below for reset password: %v", urlForgot)
-	err = message.PublishMessage(req.Email, messageparam, utils.NOTIF_EMAIL_FORGOT_PASSWORD)
+	err = u.publisherRabbitMQ.PublishMessage(req.Email, messageparam, utils.NOTIF_EMAIL_FORGOT_PASSWORD)
 	if err != nil {
 		log.Errorf("[UserService-3] ForgotPassword: %v", err)
 		return err
@@ -246,22 +400,27 @@ func (u *userService) CreateUserAccount(ctx context.Context, req entity.UserEnti
 	}
 
 	req.Password = password
-	req.Token = uuid.New().String()
 
-	err = u.repo.CreateUserAccount(ctx, req)
+	userID, err := u.repo.CreateUserAccount(ctx, req)
 	if err != nil {
 		log.Errorf("[UserService-2] CreateUserAccount: %v", err)
 		return err
 	}
 
-	urlVerify := fmt.Sprintf("http://localhost:8080/verify?token=%v", req.Token)
-	verifyMsg := fmt.Sprintf("Please verify your account by clicking the link: %s", urlVerify)
-	err = message.PublishMessage(req.Email, verifyMsg, utils.NOTIF_EMAIL_VERIFICATION)
+	token, err := u.createToken(ctx, userID, utils.NOTIF_EMAIL_VERIFICATION)
 	if err != nil {
 		log.Errorf("[UserService-3] CreateUserAccount: %v", err)
 		return err
 	}
 
+	urlVerify := fmt.Sprintf("http://localhost:8080/verify?token=%v", token)
+	verifyMsg := fmt.Sprintf("Please verify your account by clicking the link: %s", urlVerify)
+	err = u.publisherRabbitMQ.PublishMessage(req.Email, verifyMsg, utils.NOTIF_EMAIL_VERIFICATION)
+	if err != nil {
+		log.Errorf("[UserService-4] CreateUserAccount: %v", err)
+		return err
+	}
+
 	return nil
 }
 
@@ -278,43 +437,98 @@ func (u *userService) SignIn(ctx context.Context, req entity.UserEntity) (*entit
 		return nil, "", err
 	}
 
-	token, err := u.jwtService.GenerateToken(user.ID)
-	if err != nil {
-		log.Errorf("[UserService-3] SignIn: %v", err)
+	if user.PasswordResetRequired {
+		intro := "Your password has to be changed before you can sign in. Please click link below to set a new password:"
+		err = u.sendPasswordLink(ctx, user.ID, user.Email, utils.NOTIF_EMAIL_FORGOT_PASSWORD, utils.NOTIF_EMAIL_FORGOT_PASSWORD, intro)
+		if err != nil {
+			log.Errorf("[UserService-3] SignIn: %v", err)
+			return nil, "", err
+		}
+
+		err = errors.New("403")
+		log.Infof("[UserService-4] SignIn: password reset required for user %d", user.ID)
 		return nil, "", err
 	}
 
-	sessionData := map[string]interface{}{
-		"user_id":    user.ID,
-		"name":       user.Name,
-		"email":      user.Email,
-		"logged_in":  true,
-		"created_at": time.Now().String(),
-		"token":      token,
-		"role_name":  user.RoleName,
+	if err = u.startSession(ctx, user, ""); err != nil {
+		log.Errorf("[UserService-5] SignIn: %v", err)
+		return nil, "", err
 	}
 
-	jsonData, err := json.Marshal(sessionData)
+	return user, user.Token, nil
+}
+
+// startSession issues an access token and a refresh token for the user and
+// stores the session CheckToken looks up for the access token. An empty
+// familyID starts a new refresh token family, as on sign in. The tokens are
+// set on user.
+func (u *userService) startSession(ctx context.Context, user *entity.UserEntity, familyID string) error {
+	accessToken, err := u.jwtService.GenerateToken(user.ID)
 	if err != nil {
-		fmt.Println("Error encoding JSON:", err)
-		return nil, "", err
+		return err
 	}
 
-	redisConn := config.NewConfig().NewRedisClient()
-	err = redisConn.Set(ctx, token, jsonData, time.Hour*23).Err()
+	refreshToken, err := newRefreshToken()
 	if err != nil {
-		log.Errorf("[UserService-4] SignIn: %v", err)
-		return nil, "", err
+		return err
+	}
+
+	if familyID == "" {
+		familyID = uuid.New().String()
+	}
+
+	refreshTTL := u.cfg.App.RefreshTokenTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+
+	refresh := entity.RefreshTokenEntity{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(time.Duration(refreshTTL) * time.Second),
+	}
+	if err = u.repoSession.CreateRefreshToken(ctx, refreshToken, refresh); err != nil {
+		return err
+	}
+
+	session := entity.JwtUserData{
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		LoggedIn:  true,
+		CreatedAt: time.Now().String(),
+		Token:     accessToken,
+		RoleName:  user.RoleName,
+
+		RefreshFamily: familyID,
+	}
+	if err = u.repoSession.CreateSession(ctx, accessToken, session, u.jwtService.AccessTokenTTL()); err != nil {
+		return err
+	}
+
+	user.Token = accessToken
+	user.RefreshToken = refreshToken
+	return nil
+}
+
+// newRefreshToken returns an opaque random refresh token. It is not a JWT, so
+// it can never pass CheckToken as an access token.
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
 
-	return user, token, nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-func NewUserService(repo repository.UserRepositoryInterface, cfg *config.Config, jwtService JwtServiceInterface, repoToken repository.VerificationTokenRepositoryInterface) UserServiceInterface {
+func NewUserService(repo repository.UserRepositoryInterface, cfg *config.Config, jwtService JwtServiceInterface, repoToken repository.VerificationTokenRepositoryInterface, repoSession repository.SessionRepositoryInterface, publisherRabbitMQ message.PublishRabbitMQInterface) UserServiceInterface {
 	return &userService{
-		repo:       repo,
-		cfg:        cfg,
-		jwtService: jwtService,
-		repoToken:  repoToken,
+		repo:              repo,
+		cfg:               cfg,
+		jwtService:        jwtService,
+		repoToken:         repoToken,
+		repoSession:       repoSession,
+		publisherRabbitMQ: publisherRabbitMQ,
 	}
 }
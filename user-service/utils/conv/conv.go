@@ -2,6 +2,7 @@ package conv
 
 import (
 	"strconv"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -28,3 +29,29 @@ func StringToInt64(s string) (int64, error) {
 
 	return newData, nil
 }
+
+// StringToInt64List parses a comma separated list of IDs such as "1,2,3",
+// skipping empty entries and duplicates.
+func StringToInt64List(s string) ([]int64, error) {
+	ids := []int64{}
+	seen := map[int64]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}